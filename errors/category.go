@@ -0,0 +1,197 @@
+package errors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Severity is the severity of an error, derived from its categories, see SeverityOf.
+type Severity int
+
+// Severity levels, in increasing order.
+const (
+	SeverityInfo     Severity = iota // expected outcomes
+	SeverityWarning                  // client errors
+	SeverityError                    // server errors
+	SeverityCritical                 // failures needing immediate attention, e.g. a dependency down
+)
+
+// String ...
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	}
+	return "Severity(" + strconv.Itoa(int(s)) + ")"
+}
+
+// Category is a named set of codes, declared by DefineCategory, with a severity.
+type Category struct {
+	name     string
+	severity Severity
+	patterns []codePattern
+}
+
+// codePattern matches codes by digits, e.g. "401xx", or by an inclusive range, e.g. "40000-49999".
+type codePattern struct {
+	digits   string // 'x' matches any digit; empty for a range
+	from, to int
+}
+
+// categories are the categories declared by DefineCategory, for SeverityOf and CategoriesOf.
+var categories struct {
+	mutex sync.RWMutex
+	list  []*Category
+}
+
+// Built-in categories, over both HTTP statuses and 5-digit codes, e.g. 404 and 40401; they are declared before any of the application.
+var (
+	ClientError = DefineCategory("ClientError", SeverityWarning, "4xx", "4xxxx")
+	ServerError = DefineCategory("ServerError", SeverityError, "5xx", "5xxxx")
+	Auth        = DefineCategory("Auth", SeverityWarning, "401", "401xx", "403", "403xx")
+	Dependency  = DefineCategory("Dependency", SeverityCritical, "502", "502xx", "503", "503xx", "504", "504xx")
+)
+
+// DefineCategory declares a category of name and severity over the codes matched by any of patterns, which are either
+// digits with 'x' matching any digit, e.g. "4xxxx" and "5030x", exact codes, e.g. "40401", or inclusive ranges, e.g. "40000-40099".
+// A code only matches digit patterns of its own length. It panics on an invalid pattern; it is intended for init.
+func DefineCategory(name string, severity Severity, patterns ...string) *Category {
+	c := newCategory(name, severity, patterns...)
+
+	categories.mutex.Lock()
+	defer categories.mutex.Unlock()
+	categories.list = append(categories.list, c)
+	return c
+}
+
+// newCategory returns a category without declaring it; it panics on an invalid pattern.
+func newCategory(name string, severity Severity, patterns ...string) *Category {
+	c := &Category{name: name, severity: severity}
+	for _, p := range patterns {
+		cp, err := parseCodePattern(p)
+		if err != nil {
+			panic(fmt.Sprintf("category %s: %v", name, err))
+		}
+		c.patterns = append(c.patterns, cp)
+	}
+	return c
+}
+
+func parseCodePattern(p string) (codePattern, error) {
+	if lo, hi, ok := strings.Cut(p, "-"); ok {
+		from, err1 := strconv.Atoi(lo)
+		to, err2 := strconv.Atoi(hi)
+		if err1 != nil || err2 != nil || from > to {
+			return codePattern{}, fmt.Errorf("invalid code range %q", p)
+		}
+		return codePattern{from: from, to: to}, nil
+	}
+
+	p = strings.ToLower(p)
+	if p == "" {
+		return codePattern{}, fmt.Errorf("empty code pattern")
+	}
+	for _, r := range p {
+		if r != 'x' && (r < '0' || r > '9') {
+			return codePattern{}, fmt.Errorf("invalid code pattern %q", p)
+		}
+	}
+	return codePattern{digits: p}, nil
+}
+
+func (p codePattern) match(code int) bool {
+	if p.digits == "" {
+		return code >= p.from && code <= p.to
+	}
+	s := strconv.Itoa(code)
+	if len(s) != len(p.digits) {
+		return false
+	}
+	for i := range s {
+		if p.digits[i] != 'x' && p.digits[i] != s[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Name ...
+func (c *Category) Name() string {
+	return c.name
+}
+
+// Severity ...
+func (c *Category) Severity() Severity {
+	return c.severity
+}
+
+// Match reports whether code belongs to the category.
+func (c *Category) Match(code int) bool {
+	for _, p := range c.patterns {
+		if p.match(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// String ...
+func (c *Category) String() string {
+	return c.name
+}
+
+// IsCategory reports whether the code of err belongs to cat; any error that is not an ErrorIface is converted through FromError.
+func IsCategory(err error, cat *Category) bool {
+	if err == nil || cat == nil {
+		return false
+	}
+	return cat.Match(codeOf(err))
+}
+
+// CategoriesOf returns the declared categories the code of err belongs to, in declaration order.
+func CategoriesOf(err error) []*Category {
+	if err == nil {
+		return nil
+	}
+	code := codeOf(err)
+
+	categories.mutex.RLock()
+	defer categories.mutex.RUnlock()
+	var ret []*Category
+	for _, c := range categories.list {
+		if c.Match(code) {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// SeverityOf returns the severity of the last declared category the code of err belongs to, so that a category
+// declared by the application refines the built-in ones, e.g. "404xx" as SeverityInfo within ClientError.
+// It returns SeverityError if err belongs to no category, and SeverityInfo if err is nil.
+func SeverityOf(err error) Severity {
+	if err == nil {
+		return SeverityInfo
+	}
+	cats := CategoriesOf(err)
+	if len(cats) == 0 {
+		return SeverityError
+	}
+	return cats[len(cats)-1].severity
+}
+
+// codeOf returns the code of err.
+func codeOf(err error) int {
+	if e, ok := err.(ErrorIface); ok {
+		return e.GetCode()
+	}
+	return FromError(err).GetCode()
+}
@@ -0,0 +1,102 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+)
+
+// restoreCategories restores the declared categories when t ends, for tests calling DefineCategory.
+func restoreCategories(t *testing.T) {
+	categories.mutex.RLock()
+	saved := append([]*Category(nil), categories.list...)
+	categories.mutex.RUnlock()
+	t.Cleanup(func() {
+		categories.mutex.Lock()
+		categories.list = saved
+		categories.mutex.Unlock()
+	})
+}
+
+func TestCategory_Match(t *testing.T) {
+	c := newCategory("TestMatch", SeverityWarning, "5030x", "40401", "42000-42099")
+	tests := []struct {
+		name string
+		code int
+		want bool
+	}{
+		{name: "case1", code: 50301, want: true},
+		{name: "case2", code: 50311, want: false},
+		{name: "case3", code: 5030, want: false},
+		{name: "case4", code: 40401, want: true},
+		{name: "case5", code: 42050, want: true},
+		{name: "case6", code: 42100, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Match(tt.code); got != tt.want {
+				t.Errorf("Match(%d) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefineCategory_Invalid(t *testing.T) {
+	for _, pattern := range []string{"", "4xy", "500-400", "a-b"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("DefineCategory(%q) did not panic", pattern)
+				}
+			}()
+			newCategory("TestInvalid", SeverityInfo, pattern)
+		}()
+	}
+}
+
+func TestIsCategory(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		cat  *Category
+		want bool
+	}{
+		{name: "case1", err: nil, cat: Auth, want: false},
+		{name: "case2", err: New(40101, "Unauthorized", "", nil), cat: Auth, want: true},
+		{name: "case3", err: New(401, "Unauthorized", "", nil), cat: ClientError, want: true},
+		{name: "case4", err: New(40401, "NotFound", "", nil), cat: Auth, want: false},
+		{name: "case5", err: Wrap(errors.New("eof"), 50301, "Unavailable", ""), cat: Dependency, want: true},
+		{name: "case6", err: errors.New("eof"), cat: ServerError, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsCategory(tt.err, tt.cat); got != tt.want {
+				t.Errorf("IsCategory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeverityOf(t *testing.T) {
+	restoreCategories(t)
+	DefineCategory("TestNotFound", SeverityInfo, "4040x")
+	tests := []struct {
+		name string
+		err  error
+		want Severity
+	}{
+		{name: "case1", err: nil, want: SeverityInfo},
+		{name: "case2", err: New(40001, "Bad", "", nil), want: SeverityWarning},
+		{name: "case3", err: New(40402, "NotFound", "", nil), want: SeverityInfo},
+		{name: "case4", err: New(50001, "Internal", "", nil), want: SeverityError},
+		{name: "case5", err: New(503, "Unavailable", "", nil), want: SeverityCritical},
+		{name: "case6", err: New(7, "Unknown", "", nil), want: SeverityError},
+		{name: "case7", err: New(50301, "Unavailable", "", nil), want: SeverityCritical},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SeverityOf(tt.err); got != tt.want {
+				t.Errorf("SeverityOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Spec is the content of a YAML file of error definitions.
+type Spec struct {
+	Package string `yaml:"package"`
+	Service string `yaml:"service"`
+	Errors  []Def  `yaml:"errors"`
+}
+
+// Def is one error definition.
+type Def struct {
+	Code       int    `yaml:"code"`
+	Reason     string `yaml:"reason"`
+	Message    string `yaml:"message"`
+	HTTPStatus int    `yaml:"http_status"`
+}
+
+// Param is a typed parameter of a generated constructor, derived from a verb of the message format.
+type Param struct {
+	Name string
+	Type string
+}
+
+// Params returns the constructor parameters matching the verbs of the message format, validated by parseSpec.
+func (d Def) Params() (ret []Param) {
+	verbs, _ := formatVerbs(d.Message)
+	for i, verb := range verbs {
+		ret = append(ret, Param{Name: fmt.Sprintf("arg%d", i+1), Type: verbType(verb)})
+	}
+	return ret
+}
+
+// parseSpec parses and validates a YAML file of error definitions.
+func parseSpec(b []byte) (*Spec, error) {
+	spec := new(Spec)
+	if err := yaml.UnmarshalStrict(b, spec); err != nil {
+		return nil, err
+	}
+
+	codes, reasons := map[int]string{}, map[string]int{}
+	for _, d := range spec.Errors {
+		if !token.IsIdentifier(d.Reason) || !token.IsExported(d.Reason) {
+			return nil, fmt.Errorf("reason %q of code %d is not an exported Go identifier", d.Reason, d.Code)
+		}
+		if reason, ok := codes[d.Code]; ok {
+			return nil, fmt.Errorf("duplicate code %d: %s and %s", d.Code, reason, d.Reason)
+		}
+		if code, ok := reasons[d.Reason]; ok {
+			return nil, fmt.Errorf("duplicate reason %s: codes %d and %d", d.Reason, code, d.Code)
+		}
+		if _, err := formatVerbs(d.Message); err != nil {
+			return nil, fmt.Errorf("message of %s: %w", d.Reason, err)
+		}
+		codes[d.Code], reasons[d.Reason] = d.Reason, d.Code
+	}
+	return spec, nil
+}
+
+var goTemplate = template.Must(template.New("go").Parse(`// Code generated by errgen from {{.Source}}; DO NOT EDIT.
+
+package {{.Spec.Package}}
+
+import "awesome-pkg/errors"
+
+// Sentinel definitions, registered into errors.DefaultRegistry.
+var (
+{{- range .Spec.Errors}}
+	// Err{{.Reason}}: {{.Message}}
+	Err{{.Reason}} = errors.DefaultRegistry.Define({{printf "%q" $.Spec.Service}}, {{.Code}}, {{printf "%q" .Reason}}, {{printf "%q" .Message}}){{if .HTTPStatus}}.WithHTTPStatus({{.HTTPStatus}}){{end}}
+{{- end}}
+)
+{{range .Spec.Errors}}
+// New{{.Reason}} returns a new {{.Reason}} error (code {{.Code}}).
+func New{{.Reason}}({{range $i, $p := .Params}}{{if $i}}, {{end}}{{$p.Name}} {{$p.Type}}{{end}}) errors.ErrorIface {
+	return Err{{.Reason}}.NewWithSkip(2{{range .Params}}, {{.Name}}{{end}})
+}
+
+// Wrap{{.Reason}} returns a new {{.Reason}} error (code {{.Code}}) caused by cause, or nil if cause is nil.
+func Wrap{{.Reason}}(cause error{{range .Params}}, {{.Name}} {{.Type}}{{end}}) errors.ErrorIface {
+	return Err{{.Reason}}.WrapWithSkip(2, cause{{range .Params}}, {{.Name}}{{end}})
+}
+{{end}}`))
+
+// generateGo returns the formatted Go source of the constructors and sentinel definitions.
+func generateGo(spec *Spec, source string) ([]byte, error) {
+	if spec.Package == "" {
+		return nil, fmt.Errorf("package name is missing")
+	}
+
+	var buf bytes.Buffer
+	data := struct {
+		Spec   *Spec
+		Source string
+	}{spec, filepath.Base(source)}
+	if err := goTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// generateDoc returns the Markdown documentation table.
+func generateDoc(spec *Spec) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("| Code | Reason | HTTP Status | Message |\n")
+	buf.WriteString("| --- | --- | --- | --- |\n")
+	for _, d := range spec.Errors {
+		status := ""
+		if d.HTTPStatus != 0 {
+			status = fmt.Sprint(d.HTTPStatus)
+		}
+		fmt.Fprintf(&buf, "| %d | %s | %s | %s |\n", d.Code, d.Reason, status, strings.ReplaceAll(d.Message, "|", `\|`))
+	}
+	return buf.Bytes()
+}
+
+// formatVerbs returns the verbs of a fmt format, e.g. ['s', 'd'] for "user %s has %5d orders, 100%%".
+// Explicit argument indexes, e.g. "%[1]d", and '*' widths or precisions are rejected, since they do not map verbs to parameters one to one.
+func formatVerbs(format string) (ret []rune, err error) {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		// 跳过 flag、宽度和精度
+		j := i + 1
+		for j < len(format) && strings.ContainsRune("+-# 0123456789.", rune(format[j])) {
+			j++
+		}
+		if j < len(format) && (format[j] == '[' || format[j] == '*') {
+			return nil, fmt.Errorf("unsupported %q in verb at offset %d: argument indexes and '*' are not supported", format[j], i)
+		}
+		if j < len(format) && format[j] != '%' {
+			ret = append(ret, rune(format[j]))
+		}
+		i = j
+	}
+	return ret, nil
+}
+
+// verbType returns the parameter type of a verb.
+func verbType(verb rune) string {
+	switch verb {
+	case 's', 'q':
+		return "string"
+	case 'd', 'c', 'b', 'o', 'O', 'U':
+		return "int"
+	case 'f', 'F', 'e', 'E', 'g', 'G':
+		return "float64"
+	case 't':
+		return "bool"
+	default:
+		return "any"
+	}
+}
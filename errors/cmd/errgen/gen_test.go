@@ -0,0 +1,73 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{name: "case1", yaml: "service: user\nerrors:\n  - {code: 40401, reason: UserNotFound, message: user %s not found, http_status: 404}\n  - {code: 40402, reason: OrderNotFound}\n", wantErr: false},
+		{name: "case2", yaml: "errors:\n  - {code: 40401, reason: UserNotFound}\n  - {code: 40401, reason: OrderNotFound}\n", wantErr: true},
+		{name: "case3", yaml: "errors:\n  - {code: 40401, reason: UserNotFound}\n  - {code: 40402, reason: UserNotFound}\n", wantErr: true},
+		{name: "case4", yaml: "errors:\n  - {code: 40401, reason: user-not-found}\n", wantErr: true},
+		{name: "case5", yaml: "errors:\n  - {code: 40401, reason: UserNotFound, status: 404}\n", wantErr: true},
+		{name: "case6", yaml: "errors:\n  - {code: 40401, reason: UserNotFound, message: \"user %[1]s not found\"}\n", wantErr: true},
+		{name: "case7", yaml: "errors:\n  - {code: 40401, reason: UserNotFound, message: \"user %*d\"}\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseSpec([]byte(tt.yaml)); (err != nil) != tt.wantErr {
+				t.Errorf("parseSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	spec := &Spec{Package: "usererr", Service: "user", Errors: []Def{
+		{Code: 40401, Reason: "UserNotFound", Message: "user %s not found after %d tries", HTTPStatus: 404},
+	}}
+	src, err := generateGo(spec, "errors.yaml")
+	if err != nil {
+		t.Fatalf("generateGo() error = %v", err)
+	}
+	for _, want := range []string{
+		`ErrUserNotFound = errors.DefaultRegistry.Define("user", 40401, "UserNotFound", "user %s not found after %d tries").WithHTTPStatus(404)`,
+		`func NewUserNotFound(arg1 string, arg2 int) errors.ErrorIface {`,
+		`func WrapUserNotFound(cause error, arg1 string, arg2 int) errors.ErrorIface {`,
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generateGo() = %s, want it to contain %s", src, want)
+		}
+	}
+}
+
+func TestFormatVerbs(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		want    []rune
+		wantErr bool
+	}{
+		{name: "case1", format: "user %s not found", want: []rune{'s'}},
+		{name: "case2", format: "%-5d of %.2f, 100%%", want: []rune{'d', 'f'}},
+		{name: "case3", format: "no verb", want: nil},
+		{name: "case4", format: "%[2]s and %[1]s", wantErr: true},
+		{name: "case5", format: "%-*d", wantErr: true},
+		{name: "case6", format: "%.*f", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatVerbs(tt.format)
+			if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("formatVerbs() = %v, %v, want %v, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
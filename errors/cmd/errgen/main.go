@@ -0,0 +1,66 @@
+// Command errgen generates typed error constructors, sentinel definitions and a documentation table
+// from a YAML file of error definitions. It fails on duplicate codes or reasons.
+//
+// Usage:
+//
+//	//go:generate go run awesome-pkg/errors/cmd/errgen -in errors.yaml -out errors_gen.go -doc ERRORS.md
+//
+// The YAML file looks like:
+//
+//	package: usererr # optional, defaults to $GOPACKAGE set by go generate
+//	service: user
+//	errors:
+//	  - code: 40401
+//	    reason: UserNotFound
+//	    message: user %s not found
+//	    http_status: 404
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	in := flag.String("in", "errors.yaml", "YAML file of error definitions")
+	out := flag.String("out", "errors_gen.go", "generated Go file")
+	doc := flag.String("doc", "", "generated Markdown documentation table, skipped if empty")
+	pkg := flag.String("pkg", "", "package of the generated Go file, overrides the YAML file and $GOPACKAGE")
+	flag.Parse()
+
+	if err := run(*in, *out, *doc, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "errgen:", err)
+		os.Exit(1)
+	}
+}
+
+// run reads the definitions from in and writes the generated files.
+func run(in, out, doc, pkg string) error {
+	b, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	spec, err := parseSpec(b)
+	if err != nil {
+		return fmt.Errorf("%s: %w", in, err)
+	}
+	switch {
+	case pkg != "":
+		spec.Package = pkg
+	case spec.Package == "":
+		spec.Package = os.Getenv("GOPACKAGE")
+	}
+
+	src, err := generateGo(spec, in)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(out, src, 0644); err != nil {
+		return err
+	}
+	if doc != "" {
+		return os.WriteFile(doc, generateDoc(spec), 0644)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+var _ error = (*Definition)(nil) // make sure Definition can be used as the target of Is
+
+// Definition is a reusable error template, declaring a code, a reason and a message format once.
+type Definition struct {
+	code   int
+	reason string
+	format string
+	status int // HTTP status, 0 if unspecified
+
+	registry *Registry // registry the definition is declared in, if any
+}
+
+// Define returns a Definition of code and reason, whose instances format their message with format, e.g. "user %s not found".
+func Define(code int, reason, format string) *Definition {
+	return &Definition{code: code, reason: reason, format: format}
+}
+
+// Define is like the package-level Define, and also registers the definition for service; it panics on duplicates.
+func (r *Registry) Define(service string, code int, reason, format string) *Definition {
+	r.MustRegister(service, code, reason, format)
+	d := Define(code, reason, format)
+	d.registry = r
+	return d
+}
+
+// Code ...
+func (d *Definition) Code() int {
+	return d.code
+}
+
+// Reason ...
+func (d *Definition) Reason() string {
+	return d.reason
+}
+
+// Format ...
+func (d *Definition) Format() string {
+	return d.format
+}
+
+// HTTPStatus returns the HTTP status declared by WithHTTPStatus, 0 if unspecified.
+func (d *Definition) HTTPStatus() int {
+	return d.status
+}
+
+// WithHTTPStatus declares the HTTP status of the definition and returns it, for chaining after Define.
+// The status is also recorded in the registry the definition is declared in, hence used by DefaultStatusMapper.
+func (d *Definition) WithHTTPStatus(status int) *Definition {
+	d.status = status
+	if d.registry != nil {
+		d.registry.setHTTPStatus(d.code, status)
+	}
+	return d
+}
+
+// Error returns the reason; it lets a Definition be the target of Is, e.g. errors.Is(err, ErrUserNotFound).
+func (d *Definition) Error() string {
+	return d.reason
+}
+
+// New returns a new error of the definition, with the message formatted with args and fileLine captured at the call site.
+func (d *Definition) New(args ...any) ErrorIface {
+	return NewWithSkip(2, d.code, d.reason, d.message(args), nil)
+}
+
+// NewWithSkip is like New; skip is the number of stack layers to be skipped when logging the file and line info.
+// Use 1 as the base skip number and increase it by 1 for each layer of encapsulation, e.g. in generated constructors.
+func (d *Definition) NewWithSkip(skip int, args ...any) ErrorIface {
+	return NewWithSkip(skip+1, d.code, d.reason, d.message(args), nil)
+}
+
+// Wrap is like New and keeps cause as the cause of the new error. It returns nil if cause is nil.
+func (d *Definition) Wrap(cause error, args ...any) ErrorIface {
+	return d.WrapWithSkip(2, cause, args...)
+}
+
+// WrapWithSkip is like Wrap, with skip working the same way as in NewWithSkip.
+func (d *Definition) WrapWithSkip(skip int, cause error, args ...any) ErrorIface {
+	if cause == nil {
+		return nil
+	}
+	return NewWithSkip(skip+1, d.code, d.reason, d.message(args), nil, WithCause(cause))
+}
+
+// Is reports whether any error in err's chain is of the definition, i.e. has the same reason.
+func (d *Definition) Is(err error) bool {
+	return errors.Is(err, &Error{reason: d.reason})
+}
+
+// message formats the message with args; the format is kept as is without args.
+func (d *Definition) message(args []any) string {
+	if len(args) == 0 {
+		return d.format
+	}
+	return fmt.Sprintf(d.format, args...)
+}
@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDefinition_New(t *testing.T) {
+	userNotFound := Define(404, "UserNotFound", "user %s not found")
+	tests := []struct {
+		name string
+		args []any
+		want *Error
+	}{
+		{name: "case1", args: []any{"alice"}, want: &Error{code: 404, reason: "UserNotFound", message: "user alice not found"}},
+		{name: "case2", args: nil, want: &Error{code: 404, reason: "UserNotFound", message: "user %s not found"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := userNotFound.New(tt.args...)
+			if !got.Equal(tt.want) || got.GetReason() != tt.want.reason {
+				t.Errorf("Definition.New() = %v, want %v", got, tt.want)
+			}
+			if !strings.Contains(got.GetFileLine(), "define_test.go") {
+				t.Errorf("Definition.New() fileLine = %v, want the call site", got.GetFileLine())
+			}
+		})
+	}
+}
+
+func TestDefinition_Is(t *testing.T) {
+	userNotFound := Define(404, "UserNotFound", "user %s not found")
+	orderNotFound := Define(404, "OrderNotFound", "order %d not found")
+	cause := fmt.Errorf("no rows")
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "case1", err: userNotFound.New("alice"), want: true},
+		{name: "case2", err: fmt.Errorf("handler: %w", userNotFound.New("alice")), want: true},
+		{name: "case3", err: orderNotFound.New(1), want: false},
+		{name: "case4", err: userNotFound.Wrap(cause, "alice"), want: true},
+		{name: "case5", err: Wrap(userNotFound.New("alice"), 500, "Internal", ""), want: true},
+		{name: "case6", err: cause, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := userNotFound.Is(tt.err); got != tt.want {
+				t.Errorf("Definition.Is() = %v, want %v", got, tt.want)
+			}
+			if got := Is(tt.err, userNotFound); got != tt.want {
+				t.Errorf("Is(err, Definition) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := userNotFound.Wrap(nil); got != nil {
+		t.Errorf("Definition.Wrap(nil) = %v, want nil", got)
+	}
+	if !Is(userNotFound.Wrap(cause, "alice"), cause) {
+		t.Errorf("Definition.Wrap() lost the cause")
+	}
+}
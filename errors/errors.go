@@ -30,14 +30,22 @@ type ErrorIface interface {
 type Option func(*Error)
 
 // Error is a trivial implementation of ErrorIface, hence of error.
+// An Error is immutable once created, so that it is safe to share, e.g. as a package-level sentinel:
+// With and RecastError return modified copies, and GetExtraDataMap returns a copy of the map.
 type Error struct {
 	code         int
 	reason       string
-	message      string
+	message      string // public message, safe to show to users
+	internal     string // internal message for operators, see WithInternalMessage
 	stack        string
 	fileLine     string
 	data         any
 	extraDataMap map[string]any
+	cause        error
+	pcs          []uintptr // program counters captured by WithStack, resolved lazily
+	stackDepth   int       // max number of frames resolved from pcs
+	stackLimit   int       // max length in bytes of the stack rendered by Error, set by WithStackTrace
+	retry        *retryMeta
 }
 
 // GetCode ...
@@ -64,12 +72,27 @@ func (e *Error) GetMessage() string {
 	return ""
 }
 
-// GetStack ...
+// GetStack returns the stack trace loaded by WithStackTrace, or rendered from the frames captured by WithStack.
 func (e *Error) GetStack() string {
-	if e != nil {
+	if e == nil {
+		return ""
+	}
+	if e.stack != "" || len(e.pcs) == 0 {
 		return e.stack
 	}
-	return ""
+	stack := renderFrames(e.Frames())
+	if e.stackLimit > 0 && len(stack) > e.stackLimit {
+		stack = stack[:e.stackLimit]
+	}
+	return stack
+}
+
+// traceStack returns the stack printed by Error, i.e. the one loaded by WithStackTrace or carried by the wire format.
+func (e *Error) traceStack() string {
+	if e.stackLimit > 0 {
+		return e.GetStack()
+	}
+	return e.stack
 }
 
 // GetFileLine ...
@@ -88,50 +111,96 @@ func (e *Error) GetData() any {
 	return nil
 }
 
-// GetExtraDataMap ...
+// GetExtraDataMap returns a copy of the extra data.
 func (e *Error) GetExtraDataMap() map[string]any {
 	if e != nil {
-		return e.extraDataMap
+		return copyMap(e.extraDataMap)
+	}
+	return nil
+}
+
+// With returns a copy of the error with the passed-in options applied; the error itself is left untouched.
+func (e *Error) With(options ...Option) ErrorIface {
+	c := e.clone()
+	for _, opt := range options {
+		opt(c)
+	}
+	return c
+}
+
+// clone returns a copy of the error which options can be applied to; the captured frames are shared as they are never modified.
+func (e *Error) clone() *Error {
+	c := *e
+	c.extraDataMap = copyMap(e.extraDataMap)
+	return &c
+}
+
+// copyMap returns a shallow copy of m, nil if m is nil.
+func copyMap(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	ret := make(map[string]any, len(m))
+	for k, v := range m {
+		ret[k] = v
+	}
+	return ret
+}
+
+// Unwrap returns the cause of the error, if any; it makes the standard `errors.Is` and `errors.As` traverse the chain.
+func (e *Error) Unwrap() error {
+	if e != nil {
+		return e.cause
 	}
 	return nil
 }
 
-// Error returns a customized format of the entire error; it implements standard `error` interface.
+// Error returns a customized format of the entire error, including its internal message, followed by its cause chain;
+// it implements standard `error` interface. Use GetMessage for the public message only.
+// The stack loaded by WithStackTrace is included; frames captured by WithStack are not resolved here, print them with %+v.
 func (e *Error) Error() string {
-	return fmt.Sprintf(`code: %d, reason: %s, message: %s, stack: %s, file: %s, data: %#v, extraDataMap: %#v`,
-		e.code, e.reason, e.message, e.stack, e.fileLine, e.data, e.extraDataMap,
+	s := fmt.Sprintf(`code: %d, reason: %s, message: %s, stack: %s, file: %s, data: %#v, extraDataMap: %#v`,
+		e.code, e.reason, e.message, e.traceStack(), e.fileLine, e.data, e.extraDataMap,
 	)
+	if e.internal != "" {
+		s += ", internal: " + e.internal
+	}
+	if e.cause != nil {
+		s += ", cause: {" + e.cause.Error() + "}"
+	}
+	return s
 }
 
 // String returns a customized format of the entire error; it implements `fmt.Stringer` as well.
 func (e *Error) String() string {
-	return fmt.Sprintf(`code: %d, reason: %s, message: %s, stack: %s, file: %s, data: %#v, extraDataMap: %#v`,
-		e.code, e.reason, e.message, e.stack, e.fileLine, e.data, e.extraDataMap,
-	)
+	return e.Error()
 }
 
-// Is matches each error in the chain with the target value.
+// Is matches each error in the chain with the target value, an Error or a Definition, by reason.
 func (e *Error) Is(err error) bool {
+	if d, ok := err.(*Definition); ok {
+		return d.reason == e.reason
+	}
 	if se := new(Error); errors.As(err, &se) {
 		return se.reason == e.reason
 	}
 	return false
 }
 
-// DeepEqual compares code and message.
+// Equal compares code, message and internal message, so that standard errors converted by FromError stay told apart by their text.
 func (e *Error) Equal(err error) bool {
 	fe := FromError(err)
-	if e.GetCode() == fe.GetCode() && e.GetMessage() == fe.GetMessage() {
+	if e.GetCode() == fe.GetCode() && e.GetMessage() == fe.GetMessage() && InternalMessage(e) == InternalMessage(fe) {
 		return true
 	}
 	return false
 }
 
-// DeepEqual compares code, reason, message, and data.
+// DeepEqual compares code, reason, message, internal message, and data.
 func (e *Error) DeepEqual(err error) bool {
 	fe := FromError(err)
 	if e.GetCode() == fe.GetCode() && e.GetReason() == fe.GetReason() &&
-		e.GetMessage() == fe.GetMessage() && e.GetData() == fe.GetData() && reflect.DeepEqual(e.GetExtraDataMap(), fe.GetExtraDataMap()) {
+		e.GetMessage() == fe.GetMessage() && InternalMessage(e) == InternalMessage(fe) && e.GetData() == fe.GetData() && reflect.DeepEqual(e.GetExtraDataMap(), fe.GetExtraDataMap()) {
 		return true
 	}
 	return false
@@ -183,22 +252,55 @@ func NewWithSkip(skip, code int, reason, message string, data any, options ...Op
 	return &Error
 }
 
+// Wrap returns a new error with its underlying type being Error, keeping err as its cause.
+// The cause is reachable through Unwrap, hence by Is and As. It returns nil if err is nil.
+func Wrap(err error, code int, reason, message string, options ...Option) ErrorIface {
+	if err == nil {
+		return nil
+	}
+	return NewWithSkip(2, code, reason, message, nil, append([]Option{WithCause(err)}, options...)...)
+}
+
+// Is reports whether any error in err's chain matches target; it is a shortcut to the standard `errors.Is`.
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As finds the first error in err's chain that matches target; it is a shortcut to the standard `errors.As`.
+func As(err error, target any) bool {
+	return errors.As(err, target)
+}
+
+// Unwrap returns the result of calling the Unwrap method on err, if any; it is a shortcut to the standard `errors.Unwrap`.
+func Unwrap(err error) error {
+	return errors.Unwrap(err)
+}
+
 // FromError tries to convert an error to ErrorIface.
-// It supports wrapped errors.
+// It supports wrapped errors; a Multi is returned as is, and a ValidationError is converted as by its ToError.
+// Any other error gets a generic public message, its text being kept as the internal message only.
 func FromError(err error) ErrorIface {
 	if err == nil {
 		return nil
 	}
 
+	if m, ok := err.(*Multi); ok {
+		return m
+	}
+
 	if se := new(Error); errors.As(err, &se) {
 		return se
 	}
 
-	return New(500, "", err.Error(), nil)
+	if v := new(ValidationError); errors.As(err, &v) {
+		return NewWithSkip(2, ValidationCode, ValidationReason, v.message(), v.Issues(), WithCause(err))
+	}
+
+	return NewWithSkip(2, 500, "", convertedMessage(500), nil, WithInternalMessage(err.Error()), WithCause(err))
 }
 
 // FromErrorPro tries to convert an error to ErrorIface with passed in parameters.
-// It supports wrapped errors.
+// It supports wrapped errors; like FromError, the text of a standard error is kept as the internal message only.
 func FromErrorPro(err error, code int, reason string, data any, options ...Option) ErrorIface {
 	if err == nil {
 		return nil
@@ -208,10 +310,10 @@ func FromErrorPro(err error, code int, reason string, data any, options ...Optio
 		return se
 	}
 
-	return New(code, reason, err.Error(), data, options...)
+	return NewWithSkip(2, code, reason, convertedMessage(code), data, append([]Option{WithInternalMessage(err.Error()), WithCause(err)}, options...)...)
 }
 
-// RecastError tries to add addition information to an ErrorIface.
+// RecastError returns a copy of the first Error in err's chain with addition information; err itself is left untouched.
 // It DOES NOT support standard errors by design.
 func RecastError(err error, options ...Option) ErrorIface {
 	if err == nil {
@@ -219,29 +321,21 @@ func RecastError(err error, options ...Option) ErrorIface {
 	}
 
 	if se := new(Error); errors.As(err, &se) {
-		for _, opt := range options {
-			opt(se)
-		}
-		return se
+		return se.With(options...)
 	}
 
 	panic("RecastError DOES NOT support std errors")
 }
 
-// WithStackTrace loads the current stack trace information into the error of given length, max capped at 65536 [2 << 15] (which in most cases should be enough).
-// Notice that this function will intensely negatively affect the performance.
+// WithStackTrace captures the current stack trace into the error, printed by Error and GetStack up to the given length in bytes, 0 for none.
+// Like WithStack, only program counters are captured, and the frames of this package are trimmed; they are rendered when printed.
 func WithStackTrace(sizeInByte int) Option {
 	return func(err *Error) {
-		var buf [2 << 15]byte
-		if sizeInByte > len(buf) {
-			sizeInByte = len(buf)
-		}
-		length := runtime.Stack(buf[:], false)
-		if length < sizeInByte {
-			err.stack = string(buf[:length])
-		} else {
-			err.stack = string(buf[:sizeInByte])
+		if sizeInByte <= 0 {
+			return
 		}
+		err.pcs, err.stackDepth = callers(int(stackDepth.Load()))
+		err.stackLimit = sizeInByte
 	}
 }
 
@@ -256,17 +350,16 @@ func WithExtraData(key string, val any) Option {
 	}
 }
 
-// WithExtraDataMap loads the passed-in map into the error.
+// WithExtraDataMap loads a copy of the passed-in map into the error.
 func WithExtraDataMap(m map[string]any) Option {
 	return func(err *Error) {
 		if err.extraDataMap == nil {
-			err.extraDataMap = m
+			err.extraDataMap = copyMap(m)
 			return
 		}
 		for k, v := range m {
 			err.extraDataMap[k] = v
 		}
-
 	}
 }
 
@@ -291,6 +384,13 @@ func WithMessage(message string) Option {
 	}
 }
 
+// WithCause loads the passed-in cause into the error.
+func WithCause(cause error) Option {
+	return func(err *Error) {
+		err.cause = cause
+	}
+}
+
 // WithData loads the passed-in data into the error.
 func WithData(data any) Option {
 	return func(err *Error) {
@@ -2,7 +2,9 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -167,7 +169,7 @@ func TestError_Is(t *testing.T) {
 		args   args
 		want   bool
 	}{
-		{name: "case1", fields: fields{code: 500, reason: "ASD", message: "", data: nil}, args: args{err: &Error{500, "ASD", "", "", "", nil, nil}}, want: true},
+		{name: "case1", fields: fields{code: 500, reason: "ASD", message: "", data: nil}, args: args{err: &Error{code: 500, reason: "ASD"}}, want: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -186,10 +188,11 @@ func TestError_Is(t *testing.T) {
 
 func TestError_Equal(t *testing.T) {
 	type fields struct {
-		code    int
-		reason  string
-		message string
-		data    any
+		code     int
+		reason   string
+		message  string
+		internal string
+		data     any
 	}
 	type args struct {
 		err error
@@ -200,18 +203,21 @@ func TestError_Equal(t *testing.T) {
 		args   args
 		want   bool
 	}{
-		{name: "case1", fields: fields{code: 500, reason: "ASD", message: "zxc", data: nil}, args: args{err: &Error{500, "ASD", "zxc", "", "", nil, nil}}, want: true},
-		{name: "case2", fields: fields{code: 500, reason: "", message: "zxc", data: nil}, args: args{err: &Error{500, "ASD", "zxc", "", "", nil, nil}}, want: true},
-		{name: "case3", fields: fields{code: 500, reason: "", message: "", data: nil}, args: args{err: &Error{500, "ASD", "zxc", "", "", nil, nil}}, want: false},
-		{name: "case4", fields: fields{code: 501, reason: "ASD", message: "zxc", data: nil}, args: args{err: &Error{500, "ASD", "zxc", "", "", nil, nil}}, want: false},
+		{name: "case1", fields: fields{code: 500, reason: "ASD", message: "zxc", data: nil}, args: args{err: &Error{code: 500, reason: "ASD", message: "zxc"}}, want: true},
+		{name: "case2", fields: fields{code: 500, reason: "", message: "zxc", data: nil}, args: args{err: &Error{code: 500, reason: "ASD", message: "zxc"}}, want: true},
+		{name: "case3", fields: fields{code: 500, reason: "", message: "", data: nil}, args: args{err: &Error{code: 500, reason: "ASD", message: "zxc"}}, want: false},
+		{name: "case4", fields: fields{code: 501, reason: "ASD", message: "zxc", data: nil}, args: args{err: &Error{code: 500, reason: "ASD", message: "zxc"}}, want: false},
+		{name: "case5", fields: fields{code: 500, message: "Internal Server Error", internal: "connection refused"}, args: args{err: errors.New("connection refused")}, want: true},
+		{name: "case6", fields: fields{code: 500, message: "Internal Server Error", internal: "connection refused"}, args: args{err: errors.New("permission denied")}, want: false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			e := &Error{
-				code:    tt.fields.code,
-				reason:  tt.fields.reason,
-				message: tt.fields.message,
-				data:    tt.fields.data,
+				code:     tt.fields.code,
+				reason:   tt.fields.reason,
+				message:  tt.fields.message,
+				internal: tt.fields.internal,
+				data:     tt.fields.data,
 			}
 			if got := e.Equal(tt.args.err); got != tt.want {
 				t.Errorf("Error.Equal() = %v, want %v", got, tt.want)
@@ -222,10 +228,11 @@ func TestError_Equal(t *testing.T) {
 
 func TestError_DeepEqual(t *testing.T) {
 	type fields struct {
-		code    int
-		reason  string
-		message string
-		data    any
+		code     int
+		reason   string
+		message  string
+		internal string
+		data     any
 	}
 	type args struct {
 		err error
@@ -236,19 +243,21 @@ func TestError_DeepEqual(t *testing.T) {
 		args   args
 		want   bool
 	}{
-		{name: "case1", fields: fields{code: 500, reason: "ASD", message: "zxc", data: nil}, args: args{err: &Error{500, "ASD", "zxc", "", "", nil, nil}}, want: true},
-		{name: "case2", fields: fields{code: 501, reason: "ASD", message: "zxc", data: nil}, args: args{err: &Error{500, "ASD", "zxc", "", "", nil, nil}}, want: false},
-		{name: "case3", fields: fields{code: 500, reason: "", message: "zxc", data: nil}, args: args{err: &Error{500, "ASD", "zxc", "", "", nil, nil}}, want: false},
-		{name: "case4", fields: fields{code: 500, reason: "ASD", message: "zxc", data: 1234}, args: args{err: &Error{500, "ASD", "zxc", "", "", nil, nil}}, want: false},
-		{name: "case5", fields: fields{code: 500, reason: "ASD", message: "zxc", data: 12345}, args: args{err: &Error{500, "ASD", "zxc", "", "", 12345, nil}}, want: true},
+		{name: "case1", fields: fields{code: 500, reason: "ASD", message: "zxc", data: nil}, args: args{err: &Error{code: 500, reason: "ASD", message: "zxc"}}, want: true},
+		{name: "case2", fields: fields{code: 501, reason: "ASD", message: "zxc", data: nil}, args: args{err: &Error{code: 500, reason: "ASD", message: "zxc"}}, want: false},
+		{name: "case3", fields: fields{code: 500, reason: "", message: "zxc", data: nil}, args: args{err: &Error{code: 500, reason: "ASD", message: "zxc"}}, want: false},
+		{name: "case4", fields: fields{code: 500, reason: "ASD", message: "zxc", data: 1234}, args: args{err: &Error{code: 500, reason: "ASD", message: "zxc"}}, want: false},
+		{name: "case5", fields: fields{code: 500, reason: "ASD", message: "zxc", data: 12345}, args: args{err: &Error{code: 500, reason: "ASD", message: "zxc", data: 12345}}, want: true},
+		{name: "case6", fields: fields{code: 500, message: "Internal Server Error", internal: "connection refused"}, args: args{err: errors.New("permission denied")}, want: false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			e := &Error{
-				code:    tt.fields.code,
-				reason:  tt.fields.reason,
-				message: tt.fields.message,
-				data:    tt.fields.data,
+				code:     tt.fields.code,
+				reason:   tt.fields.reason,
+				message:  tt.fields.message,
+				internal: tt.fields.internal,
+				data:     tt.fields.data,
 			}
 			if got := e.DeepEqual(tt.args.err); got != tt.want {
 				t.Errorf("Error.DeepEqual() = %v, want %v", got, tt.want)
@@ -269,7 +278,7 @@ func TestNew(t *testing.T) {
 		args args
 		want *Error
 	}{
-		{name: "case1", args: args{code: 500, reason: "ASD", message: "", data: 123}, want: &Error{500, "ASD", "", "", "", 123, nil}},
+		{name: "case1", args: args{code: 500, reason: "ASD", message: "", data: 123}, want: &Error{code: 500, reason: "ASD", data: 123}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -289,8 +298,8 @@ func TestFromError(t *testing.T) {
 		args args
 		want *Error
 	}{
-		{name: "case1", args: args{err: &Error{code: 500, reason: "ASD", message: "", data: 123}}, want: &Error{500, "ASD", "", "", "", 123, nil}},
-		{name: "case2", args: args{err: errors.New("asd")}, want: &Error{500, "", "asd", "", "", []struct{}{}, nil}},
+		{name: "case1", args: args{err: &Error{code: 500, reason: "ASD", message: "", data: 123}}, want: &Error{code: 500, reason: "ASD", data: 123}},
+		{name: "case2", args: args{err: errors.New("asd")}, want: &Error{code: 500, message: "Internal Server Error", internal: "asd", data: []struct{}{}}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -313,8 +322,8 @@ func TestFromErrorPro(t *testing.T) {
 		args args
 		want *Error
 	}{
-		{name: "case1", args: args{err: &Error{code: 500, reason: "ASD", message: "", data: 123}, code: 500, reason: "ASD", data: 123}, want: &Error{500, "ASD", "", "", "", 123, nil}},
-		{name: "case2", args: args{err: errors.New("asd"), code: 500, reason: "ASD", data: nil}, want: &Error{500, "ASD", "asd", "", "", []struct{}{}, nil}},
+		{name: "case1", args: args{err: &Error{code: 500, reason: "ASD", message: "", data: 123}, code: 500, reason: "ASD", data: 123}, want: &Error{code: 500, reason: "ASD", data: 123}},
+		{name: "case2", args: args{err: errors.New("asd"), code: 500, reason: "ASD", data: nil}, want: &Error{code: 500, reason: "ASD", message: "Internal Server Error", internal: "asd", data: []struct{}{}}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -324,3 +333,95 @@ func TestFromErrorPro(t *testing.T) {
 		})
 	}
 }
+
+func TestWrap(t *testing.T) {
+	std := errors.New("connection refused")
+	inner := New(503, "Unavailable", "db down", nil)
+	type args struct {
+		err     error
+		code    int
+		reason  string
+		message string
+	}
+	tests := []struct {
+		name   string
+		args   args
+		target error
+		want   bool
+	}{
+		{name: "case1", args: args{err: std, code: 500, reason: "DBError", message: "query failed"}, target: std, want: true},
+		{name: "case2", args: args{err: fmt.Errorf("layer: %w", std), code: 500, reason: "DBError", message: "query failed"}, target: std, want: true},
+		{name: "case3", args: args{err: inner, code: 500, reason: "DBError", message: "query failed"}, target: &Error{reason: "Unavailable"}, want: true},
+		{name: "case4", args: args{err: inner, code: 500, reason: "DBError", message: "query failed"}, target: &Error{reason: "DBError"}, want: true},
+		{name: "case5", args: args{err: std, code: 500, reason: "DBError", message: "query failed"}, target: &Error{reason: "Unavailable"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Wrap(tt.args.err, tt.args.code, tt.args.reason, tt.args.message)
+			if Is(got, tt.target) != tt.want {
+				t.Errorf("Is(Wrap(), %v) = %v, want %v", tt.target, !tt.want, tt.want)
+			}
+			if Unwrap(got) != tt.args.err {
+				t.Errorf("Unwrap(Wrap()) = %v, want %v", Unwrap(got), tt.args.err)
+			}
+			if !strings.HasSuffix(got.Error(), "cause: {"+tt.args.err.Error()+"}") {
+				t.Errorf("Wrap().Error() = %v, want the cause rendered", got.Error())
+			}
+		})
+	}
+
+	if got := Wrap(nil, 500, "", ""); got != nil {
+		t.Errorf("Wrap(nil) = %v, want nil", got)
+	}
+}
+
+func TestFromErrorPro_Cause(t *testing.T) {
+	std := errors.New("asd")
+	got := FromErrorPro(fmt.Errorf("wrapped: %w", std), 500, "ASD", nil)
+	if !Is(got, std) {
+		t.Errorf("FromErrorPro() lost the cause %v", std)
+	}
+	var se *Error
+	if !As(got, &se) || se.GetReason() != "ASD" {
+		t.Errorf("As(FromErrorPro()) = %v, want reason ASD", se)
+	}
+}
+
+func TestError_With(t *testing.T) {
+	sentinel := New(404, "NotFound", "not found", nil, WithExtraData("k", "v"))
+	tests := []struct {
+		name        string
+		options     []Option
+		wantCode    int
+		wantMessage string
+		wantExtra   map[string]any
+	}{
+		{name: "case1", options: nil, wantCode: 404, wantMessage: "not found", wantExtra: map[string]any{"k": "v"}},
+		{name: "case2", options: []Option{WithCode(40401), WithMessage("user not found")}, wantCode: 40401, wantMessage: "user not found", wantExtra: map[string]any{"k": "v"}},
+		{name: "case3", options: []Option{WithExtraData("id", 1)}, wantCode: 404, wantMessage: "not found", wantExtra: map[string]any{"k": "v", "id": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RecastError(sentinel, tt.options...)
+			if got == sentinel {
+				t.Fatalf("RecastError() returned the sentinel itself")
+			}
+			if got.GetCode() != tt.wantCode || got.GetMessage() != tt.wantMessage || !reflect.DeepEqual(got.GetExtraDataMap(), tt.wantExtra) {
+				t.Errorf("RecastError() = %v, want code %d, message %q, extra %v", got, tt.wantCode, tt.wantMessage, tt.wantExtra)
+			}
+			if sentinel.GetCode() != 404 || sentinel.GetMessage() != "not found" || !reflect.DeepEqual(sentinel.GetExtraDataMap(), map[string]any{"k": "v"}) {
+				t.Errorf("RecastError() mutated the sentinel: %v", sentinel)
+			}
+		})
+	}
+}
+
+func TestWithExtraDataMap_Copy(t *testing.T) {
+	m := map[string]any{"k": "v"}
+	e := New(500, "Internal", "", nil, WithExtraDataMap(m))
+	m["k"] = "changed"
+	e.GetExtraDataMap()["k"] = "changed"
+	if got := e.GetExtraDataMap()["k"]; got != "v" {
+		t.Errorf("GetExtraDataMap()[k] = %v, want %v", got, "v")
+	}
+}
@@ -0,0 +1,115 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+)
+
+// StatusMapper maps an error code to an HTTP status.
+type StatusMapper func(code int) int
+
+var (
+	statusMapper atomic.Value // StatusMapper
+	debugMode    atomic.Bool
+)
+
+// SetStatusMapper sets the StatusMapper used by WriteHTTP; nil restores DefaultStatusMapper.
+func SetStatusMapper(m StatusMapper) {
+	if m == nil {
+		m = DefaultStatusMapper
+	}
+	statusMapper.Store(m)
+}
+
+// SetDebug turns on or off the debug mode, in which WriteHTTP also writes stack and fileLine.
+func SetDebug(debug bool) {
+	debugMode.Store(debug)
+}
+
+// HTTPStatus returns the HTTP status of code through the StatusMapper set by SetStatusMapper.
+func HTTPStatus(code int) int {
+	if m, ok := statusMapper.Load().(StatusMapper); ok {
+		return m(code)
+	}
+	return DefaultStatusMapper(code)
+}
+
+// DefaultStatusMapper maps code to the HTTP status declared in DefaultRegistry if any,
+// then to code itself if it is an HTTP error status, then to its leading three digits, e.g. 404 for 40401;
+// anything else is 500, including codes leading with a 1xx-3xx status, e.g. 10001, so that an error is never sent with a success status.
+func DefaultStatusMapper(code int) int {
+	if e, ok := DefaultRegistry.Lookup(code); ok && e.HTTPStatus != 0 {
+		return e.HTTPStatus
+	}
+	for code >= 1000 {
+		code /= 10
+	}
+	if code >= 400 && code <= 599 {
+		return code
+	}
+	return http.StatusInternalServerError
+}
+
+// HTTPBody is the stable JSON envelope written by WriteHTTP.
+type HTTPBody struct {
+	Code     int        `json:"code"`
+	Reason   string     `json:"reason"`
+	Message  string     `json:"message"`
+	Data     any        `json:"data"`
+	Stack    string     `json:"stack,omitempty"`
+	FileLine string     `json:"file,omitempty"`
+	Errors   []HTTPBody `json:"errors,omitempty"` // members of a Multi
+}
+
+// NewHTTPBody returns the envelope of err; stack and fileLine are only filled in debug mode.
+// The members of a Multi are listed in Errors.
+func NewHTTPBody(err ErrorIface) HTTPBody {
+	body := HTTPBody{
+		Code:    err.GetCode(),
+		Reason:  err.GetReason(),
+		Message: err.GetMessage(),
+		Data:    err.GetData(),
+	}
+	if debugMode.Load() {
+		body.Stack = err.GetStack()
+		body.FileLine = err.GetFileLine()
+	}
+	if m, ok := err.(*Multi); ok {
+		for _, e := range m.Errors() {
+			body.Errors = append(body.Errors, NewHTTPBody(e))
+		}
+	}
+	return body
+}
+
+// WriteHTTP writes err to w as a JSON envelope, with the HTTP status mapped from its code.
+// The message is localized by DefaultCatalog following the `Accept-Language` header of r, see Localize.
+// The `Retry-After` header is set if err declares it, see WithRetryAfter.
+// Any error that is not an ErrorIface is converted through FromError. Nothing is written if err is nil.
+func WriteHTTP(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+	e := localizeRequest(w, r, err)
+
+	body := NewHTTPBody(e)
+	b, jerr := json.Marshal(body)
+	if jerr != nil {
+		// data 无法序列化时仍然输出其余字段
+		body.Data = nil
+		for i := range body.Errors {
+			body.Errors[i].Data = nil
+		}
+		b, _ = json.Marshal(body)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	setRetryAfter(w.Header(), e)
+	w.WriteHeader(HTTPStatus(e.GetCode()))
+	if r != nil && r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(append(b, '\n'))
+}
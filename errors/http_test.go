@@ -0,0 +1,87 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDefaultStatusMapper(t *testing.T) {
+	r := DefaultRegistry
+	DefaultRegistry = NewRegistry()
+	defer func() { DefaultRegistry = r }()
+	DefaultRegistry.Define("user", 40901, "UserLocked", "").WithHTTPStatus(423)
+
+	tests := []struct {
+		name string
+		code int
+		want int
+	}{
+		{name: "case1", code: 404, want: 404},
+		{name: "case2", code: 40401, want: 404},
+		{name: "case3", code: 503001, want: 503},
+		{name: "case4", code: 40901, want: 423},
+		{name: "case5", code: 0, want: 500},
+		{name: "case6", code: 7001, want: 500},
+		{name: "case7", code: 10001, want: 500},
+		{name: "case8", code: 2001, want: 500},
+		{name: "case9", code: 3021, want: 500},
+		{name: "case10", code: 200, want: 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultStatusMapper(tt.code); got != tt.want {
+				t.Errorf("DefaultStatusMapper() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteHTTP(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		debug      bool
+		mapper     StatusMapper
+		wantStatus int
+		wantBody   map[string]any
+	}{
+		{name: "case1", err: New(40401, "UserNotFound", "user not found", map[string]any{"id": "1"}), wantStatus: 404,
+			wantBody: map[string]any{"code": 40401.0, "reason": "UserNotFound", "message": "user not found", "data": map[string]any{"id": "1"}}},
+		{name: "case2", err: errors.New("boom"), wantStatus: 500,
+			wantBody: map[string]any{"code": 500.0, "reason": "", "message": "Internal Server Error", "data": map[string]any{}}},
+		{name: "case3", err: New(40401, "UserNotFound", "", nil), mapper: func(int) int { return 410 }, wantStatus: 410,
+			wantBody: map[string]any{"code": 40401.0, "reason": "UserNotFound", "message": "", "data": map[string]any{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetStatusMapper(tt.mapper)
+			defer SetStatusMapper(nil)
+
+			w := httptest.NewRecorder()
+			WriteHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil), tt.err)
+			if w.Code != tt.wantStatus {
+				t.Errorf("WriteHTTP() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			var got map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || !reflect.DeepEqual(got, tt.wantBody) {
+				t.Errorf("WriteHTTP() body = %s, want %v", w.Body.Bytes(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestWriteHTTP_Debug(t *testing.T) {
+	SetDebug(true)
+	defer SetDebug(false)
+
+	w := httptest.NewRecorder()
+	WriteHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil), New(500, "", "", nil))
+	var got HTTPBody
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got.FileLine == "" {
+		t.Errorf("WriteHTTP() in debug mode = %s, want file", w.Body.Bytes())
+	}
+}
@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	_ InternalIface = (*Error)(nil) // make sure Error implements InternalIface
+	_ InternalIface = (*Multi)(nil) // make sure Multi implements InternalIface
+)
+
+// InternalIface is implemented by errors carrying an internal message for operators, see WithInternalMessage.
+// The message returned by GetMessage is the public one, safe to show to users.
+type InternalIface interface {
+	GetInternalMessage() string
+}
+
+// GetInternalMessage ...
+func (e *Error) GetInternalMessage() string {
+	if e != nil {
+		return e.internal
+	}
+	return ""
+}
+
+// GetInternalMessage returns the internal messages of the members joined by "; ".
+func (m *Multi) GetInternalMessage() string {
+	var msgs []string
+	for _, e := range m.Errors() {
+		if msg := InternalMessage(e); msg != "" {
+			msgs = append(msgs, msg)
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// WithInternalMessage loads the passed-in internal message into the error; it is logged, but never rendered by WriteHTTP, WriteProblem and MarshalJSON.
+func WithInternalMessage(message string) Option {
+	return func(err *Error) {
+		err.internal = message
+	}
+}
+
+// WithInternalMessagef is like WithInternalMessage, with the message formatted from format and args.
+func WithInternalMessagef(format string, args ...any) Option {
+	return WithInternalMessage(fmt.Sprintf(format, args...))
+}
+
+// InternalMessage returns the internal message of the first error in err's chain having one, e.g. for logs.
+func InternalMessage(err error) string {
+	var ie InternalIface
+	for err != nil {
+		if errors.As(err, &ie) {
+			if msg := ie.GetInternalMessage(); msg != "" {
+				return msg
+			}
+			if next, ok := ie.(interface{ Unwrap() error }); ok {
+				err = next.Unwrap()
+				continue
+			}
+		}
+		break
+	}
+	return ""
+}
+
+// convertedMessage is the public message of a standard error converted to code, i.e. the text of its HTTP status,
+// since the text of the error itself may leak internal details, e.g. addresses.
+func convertedMessage(code int) string {
+	return http.StatusText(HTTPStatus(code))
+}
@@ -0,0 +1,88 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInternalMessage(t *testing.T) {
+	multi := new(Multi)
+	multi.Add(New(400, "Bad", "", nil, WithInternalMessage("a")))
+	multi.Add(New(400, "Bad", "", nil))
+	multi.Add(New(400, "Bad", "", nil, WithInternalMessagef("b%d", 2)))
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "case1", err: nil, want: ""},
+		{name: "case2", err: errors.New("eof"), want: ""},
+		{name: "case3", err: New(500, "Internal", "try later", nil, WithInternalMessage("db down")), want: "db down"},
+		{name: "case4", err: Wrap(New(503, "Unavailable", "", nil, WithInternalMessage("db down")), 500, "Internal", "try later"), want: "db down"},
+		{name: "case5", err: multi, want: "a; b2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InternalMessage(tt.err); got != tt.want {
+				t.Errorf("InternalMessage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInternalMessage_NotRendered(t *testing.T) {
+	err := New(500, "Internal", "try later", nil, WithInternalMessage("secret"))
+	if !strings.Contains(err.Error(), "internal: secret") {
+		t.Errorf("Error() = %v, want the internal message", err.Error())
+	}
+
+	rec := httptest.NewRecorder()
+	WriteHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil), err)
+	if strings.Contains(rec.Body.String(), "secret") {
+		t.Errorf("WriteHTTP() body = %s, leaks the internal message", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	WriteProblem(rec, httptest.NewRequest(http.MethodGet, "/", nil), err)
+	if strings.Contains(rec.Body.String(), "secret") {
+		t.Errorf("WriteProblem() body = %s, leaks the internal message", rec.Body.String())
+	}
+
+	if b, _ := json.Marshal(err); strings.Contains(string(b), "secret") {
+		t.Errorf("json.Marshal() = %s, leaks the internal message", b)
+	}
+}
+
+func TestInternalMessage_StdErrors(t *testing.T) {
+	const secret = "dial tcp 10.0.0.5:5432: connection refused"
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "case1", err: errors.New(secret)},
+		{name: "case2", err: fmt.Errorf("query: %v", New(503, "Unavailable", "try later", nil, WithInternalMessage(secret)))},
+		{name: "case3", err: Wrap(errors.New(secret), 503, "Unavailable", "try later")},
+		{name: "case4", err: FromErrorPro(errors.New(secret), 40401, "NotFound", nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			WriteHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil), tt.err)
+			if strings.Contains(rec.Body.String(), "10.0.0.5") {
+				t.Errorf("WriteHTTP() body = %s, leaks the error text", rec.Body.String())
+			}
+			if b, _ := Marshal(tt.err); strings.Contains(string(b), "10.0.0.5") {
+				t.Errorf("Marshal() = %s, leaks the error text", b)
+			}
+			if got := FromError(tt.err).Error(); !strings.Contains(got, secret) {
+				t.Errorf("FromError().Error() = %q, want the error text kept for logs", got)
+			}
+		})
+	}
+}
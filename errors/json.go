@@ -0,0 +1,168 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+var (
+	_ json.Marshaler   = (*Error)(nil) // make sure Error implements json.Marshaler
+	_ json.Unmarshaler = (*Error)(nil) // make sure Error implements json.Unmarshaler
+)
+
+// WireVersion is the version of the JSON wire format written by MarshalJSON.
+const WireVersion = 1
+
+// wireError is the JSON wire format of an Error, for cross-service transport.
+// A cause that is not an Error is dropped, as its text may leak internal details;
+// the internal message and the panic value are never carried either, see WithInternalMessage and PanicKey.
+type wireError struct {
+	Version      int            `json:"v"`
+	Code         int            `json:"code"`
+	Reason       string         `json:"reason"`
+	Message      string         `json:"message"`
+	Data         any            `json:"data,omitempty"`
+	ExtraDataMap map[string]any `json:"extra,omitempty"`
+	Stack        string         `json:"stack,omitempty"`
+	FileLine     string         `json:"file,omitempty"`
+	Cause        *wireError     `json:"cause,omitempty"`
+	Errors       []*wireError   `json:"errors,omitempty"` // members of a Multi
+}
+
+// MarshalOption configures Marshal and Unmarshal.
+type MarshalOption func(*marshalConfig)
+
+type marshalConfig struct {
+	stripDebug bool
+}
+
+// StripDebug drops stack and fileLine along the whole chain, e.g. at trust boundaries.
+func StripDebug() MarshalOption {
+	return func(cfg *marshalConfig) {
+		cfg.stripDebug = true
+	}
+}
+
+// MarshalJSON encodes the error and its cause chain in the versioned wire format.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toWire(e, &marshalConfig{}))
+}
+
+// UnmarshalJSON decodes the error and its cause chain from the versioned wire format.
+func (e *Error) UnmarshalJSON(b []byte) error {
+	var w wireError
+	if err := json.Unmarshal(b, &w); err != nil {
+		return err
+	}
+	d, err := fromWire(&w, &marshalConfig{})
+	if err != nil {
+		return err
+	}
+	*e = *d
+	return nil
+}
+
+// Marshal encodes err in the versioned wire format; a Multi is encoded with the list of its members.
+// Any error that is not an ErrorIface is converted through FromError.
+func Marshal(err error, options ...MarshalOption) ([]byte, error) {
+	cfg := &marshalConfig{}
+	for _, opt := range options {
+		opt(cfg)
+	}
+	if m, ok := err.(*Multi); ok {
+		return json.Marshal(multiToWire(m, cfg))
+	}
+	return json.Marshal(toWire(errorOf(err), cfg))
+}
+
+// Unmarshal decodes an error encoded in the versioned wire format; a list of members is decoded as a Multi.
+func Unmarshal(b []byte, options ...MarshalOption) (ErrorIface, error) {
+	cfg := &marshalConfig{}
+	for _, opt := range options {
+		opt(cfg)
+	}
+	var w wireError
+	if err := json.Unmarshal(b, &w); err != nil {
+		return nil, err
+	}
+	if len(w.Errors) > 0 {
+		if w.Version > WireVersion {
+			return nil, fmt.Errorf("unsupported error wire version %d", w.Version)
+		}
+		m := new(Multi)
+		for _, we := range w.Errors {
+			e, err := fromWire(we, cfg)
+			if err != nil {
+				return nil, err
+			}
+			m.Add(e)
+		}
+		return m, nil
+	}
+	return fromWire(&w, cfg)
+}
+
+// errorOf returns err as an *Error, converting it through FromError if needed.
+func errorOf(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	if e, ok := FromError(err).(*Error); ok {
+		return e
+	}
+	return nil
+}
+
+func toWire(e *Error, cfg *marshalConfig) *wireError {
+	if e == nil {
+		return nil
+	}
+	w := &wireError{
+		Version:      WireVersion,
+		Code:         e.GetCode(),
+		Reason:       e.GetReason(),
+		Message:      e.GetMessage(),
+		Data:         e.GetData(),
+		ExtraDataMap: publicExtraData(e.GetExtraDataMap()),
+	}
+	if w.Data == (struct{}{}) {
+		w.Data = nil
+	}
+	if !cfg.stripDebug {
+		w.Stack = e.GetStack()
+		w.FileLine = e.GetFileLine()
+	}
+
+	if cause, ok := e.cause.(*Error); ok {
+		w.Cause = toWire(cause, cfg)
+	}
+	return w
+}
+
+func fromWire(w *wireError, cfg *marshalConfig) (*Error, error) {
+	if w.Version > WireVersion {
+		return nil, fmt.Errorf("unsupported error wire version %d", w.Version)
+	}
+	e := &Error{
+		code:         w.Code,
+		reason:       w.Reason,
+		message:      w.Message,
+		data:         w.Data,
+		extraDataMap: w.ExtraDataMap,
+	}
+	if e.data == nil {
+		e.data = struct{}{}
+	}
+	if !cfg.stripDebug {
+		e.stack = w.Stack
+		e.fileLine = w.FileLine
+	}
+	if w.Cause != nil {
+		cause, err := fromWire(w.Cause, cfg)
+		if err != nil {
+			return nil, err
+		}
+		e.cause = cause
+	}
+	return e, nil
+}
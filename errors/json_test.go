@@ -0,0 +1,79 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestError_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *Error
+		want string
+	}{
+		{name: "case1", err: &Error{code: 404, reason: "NotFound", message: "m", data: struct{}{}, fileLine: "a.go:1"},
+			want: `{"v":1,"code":404,"reason":"NotFound","message":"m","file":"a.go:1"}`},
+		{name: "case2", err: &Error{code: 500, reason: "Internal", data: map[string]int{"n": 1}, extraDataMap: map[string]any{"k": "v"}, cause: errors.New("eof")},
+			want: `{"v":1,"code":500,"reason":"Internal","message":"","data":{"n":1},"extra":{"k":"v"}}`},
+		{name: "case3", err: &Error{code: 500, reason: "Internal", cause: &Error{code: 503, reason: "Unavailable", message: "db"}},
+			want: `{"v":1,"code":500,"reason":"Internal","message":"","cause":{"v":1,"code":503,"reason":"Unavailable","message":"db"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.err)
+			if err != nil || string(got) != tt.want {
+				t.Errorf("json.Marshal() = %s, %v, want %s", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestError_UnmarshalJSON(t *testing.T) {
+	want := New(500, "Internal", "query failed", map[string]any{"n": 1.0}, WithExtraData("k", "v"), WithCause(New(503, "Unavailable", "db", nil)))
+	b, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	got := new(Error)
+	if err := json.Unmarshal(b, got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !got.Equal(want) || got.GetReason() != want.GetReason() ||
+		!reflect.DeepEqual(got.GetData(), want.GetData()) || !reflect.DeepEqual(got.GetExtraDataMap(), want.GetExtraDataMap()) {
+		t.Errorf("json.Unmarshal() = %v, want %v", got, want)
+	}
+	if got.GetFileLine() != want.GetFileLine() {
+		t.Errorf("json.Unmarshal() fileLine = %v, want %v", got.GetFileLine(), want.GetFileLine())
+	}
+	if !Is(got, &Error{reason: "Unavailable"}) {
+		t.Errorf("json.Unmarshal() lost the cause chain: %v", got)
+	}
+
+	if err := json.Unmarshal([]byte(`{"v":2,"code":500}`), new(Error)); err == nil {
+		t.Errorf("json.Unmarshal() accepted an unsupported version")
+	}
+}
+
+func TestMarshal_StripDebug(t *testing.T) {
+	err := New(500, "Internal", "", nil, WithCause(New(503, "Unavailable", "", nil)))
+	b, merr := Marshal(err, StripDebug())
+	if merr != nil {
+		t.Fatalf("Marshal() error = %v", merr)
+	}
+	got, uerr := Unmarshal(b)
+	if uerr != nil {
+		t.Fatalf("Unmarshal() error = %v", uerr)
+	}
+	if got.GetFileLine() != "" || Unwrap(got).(ErrorIface).GetFileLine() != "" {
+		t.Errorf("Marshal(StripDebug()) = %s, want no file", b)
+	}
+
+	b, _ = Marshal(err)
+	got, _ = Unmarshal(b, StripDebug())
+	if got.GetFileLine() != "" {
+		t.Errorf("Unmarshal(StripDebug()) fileLine = %v, want none", got.GetFileLine())
+	}
+}
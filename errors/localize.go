@@ -0,0 +1,202 @@
+package errors
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Catalog holds the localized messages of errors, keyed by reason and language tag.
+// A message is a text/template executed with the data of the error, e.g. "用户 {{.name}} 不存在";
+// the message of the error is kept if the template fails, e.g. on a missing parameter.
+type Catalog struct {
+	mutex    sync.RWMutex
+	messages map[string]map[string]*template.Template // reason -> language tag -> message
+	fallback string
+}
+
+// DefaultCatalog is the Catalog used by Localize, WriteHTTP and WriteProblem, falling back to English.
+var DefaultCatalog = NewCatalog("en")
+
+// NewCatalog returns a pointer to a new empty Catalog, falling back to the language tag fallback if no accepted language matches.
+func NewCatalog(fallback string) *Catalog {
+	return &Catalog{messages: map[string]map[string]*template.Template{}, fallback: normalizeTag(fallback)}
+}
+
+// Add adds the message of reason in the language tag lang, e.g. "zh-CN", replacing any previous one.
+func (c *Catalog) Add(reason, lang, message string) error {
+	lang = normalizeTag(lang)
+	tpl, err := template.New(reason + "/" + lang).Option("missingkey=error").Parse(message)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.messages[reason] == nil {
+		c.messages[reason] = map[string]*template.Template{}
+	}
+	c.messages[reason][lang] = tpl
+	return nil
+}
+
+// MustAdd is like Add but panics if message is not a valid template; it is intended for init.
+func (c *Catalog) MustAdd(reason, lang, message string) *Catalog {
+	if err := c.Add(reason, lang, message); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// AddMessages adds the messages of reason keyed by language tag.
+func (c *Catalog) AddMessages(reason string, messages map[string]string) error {
+	for lang, message := range messages {
+		if err := c.Add(reason, lang, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Localize returns a copy of err with its message localized by DefaultCatalog, see Catalog.Localize.
+func Localize(err error, acceptLanguage string) ErrorIface {
+	e, _ := DefaultCatalog.localize(err, acceptLanguage)
+	return e
+}
+
+// Localize returns a copy of err with its message localized in the best language matching acceptLanguage,
+// the value of an `Accept-Language` header, e.g. "zh-CN,zh;q=0.9,en;q=0.8".
+// Each accepted language tag falls back to its prefixes, e.g. "zh-Hans-CN" to "zh-Hans" then "zh", before the next one,
+// and the fallback language of the catalog is tried last. The members of a Multi are localized each.
+// err is returned as is if no message matches, and any error that is not an ErrorIface is converted through FromError.
+func (c *Catalog) Localize(err error, acceptLanguage string) ErrorIface {
+	e, _ := c.localize(err, acceptLanguage)
+	return e
+}
+
+// localize is Localize, also returning the language tag of the message, empty if not localized.
+func (c *Catalog) localize(err error, acceptLanguage string) (ErrorIface, string) {
+	if err == nil {
+		return nil, ""
+	}
+	e, ok := err.(ErrorIface)
+	if !ok {
+		e = FromError(err)
+	}
+
+	chain := c.chain(acceptLanguage)
+	switch x := e.(type) {
+	case *Error:
+		if msg, lang, ok := c.message(x.reason, x.data, chain); ok {
+			return x.With(WithMessage(msg)), lang
+		}
+	case *Multi:
+		ret, lang := &Multi{rule: x.rule}, ""
+		for _, member := range x.Errors() {
+			localized, l := c.localize(member, acceptLanguage)
+			if lang == "" {
+				lang = l
+			}
+			ret.Add(localized)
+		}
+		return ret, lang
+	}
+	return e, ""
+}
+
+// message executes the first message of reason in the languages of chain.
+func (c *Catalog) message(reason string, data any, chain []string) (msg, lang string, ok bool) {
+	var tpl *template.Template
+	c.mutex.RLock()
+	for _, lang = range chain {
+		if tpl = c.messages[reason][lang]; tpl != nil {
+			break
+		}
+	}
+	c.mutex.RUnlock()
+	if tpl == nil {
+		return "", "", false
+	}
+
+	if data == (struct{}{}) {
+		data = nil
+	}
+	var sb strings.Builder
+	if err := tpl.Execute(&sb, data); err != nil {
+		// 参数缺失等导致渲染失败时保留原消息
+		return "", "", false
+	}
+	return sb.String(), lang, true
+}
+
+// chain returns the language tags to try in order for acceptLanguage.
+func (c *Catalog) chain(acceptLanguage string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var accepted []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag = normalizeTag(tag); tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			accepted = append(accepted, weighted{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool { return accepted[i].q > accepted[j].q })
+
+	var ret []string
+	seen := map[string]bool{}
+	add := func(tag string) {
+		for ; tag != ""; tag, _, _ = cutLast(tag, "-") {
+			if !seen[tag] {
+				seen[tag] = true
+				ret = append(ret, tag)
+			}
+		}
+	}
+	for _, a := range accepted {
+		add(a.tag)
+	}
+	add(c.fallback)
+	return ret
+}
+
+// localizeRequest localizes err by DefaultCatalog following the `Accept-Language` header of r, for WriteHTTP and WriteProblem;
+// the headers of the response to w are set accordingly if the message is localized.
+func localizeRequest(w http.ResponseWriter, r *http.Request, err error) ErrorIface {
+	acceptLanguage := ""
+	if r != nil {
+		acceptLanguage = r.Header.Get("Accept-Language")
+	}
+	e, lang := DefaultCatalog.localize(err, acceptLanguage)
+	if lang != "" {
+		w.Header().Set("Content-Language", lang)
+		w.Header().Add("Vary", "Accept-Language")
+	}
+	return e
+}
+
+// normalizeTag lower-cases a language tag and separates its subtags with "-", e.g. "zh_CN" to "zh-cn".
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// cutLast slices s around the last instance of sep, returning the text before and after it.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return "", s, false
+}
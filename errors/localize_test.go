@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCatalog_chain(t *testing.T) {
+	c := NewCatalog("en")
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		want           []string
+	}{
+		{name: "case1", acceptLanguage: "", want: []string{"en"}},
+		{name: "case2", acceptLanguage: "zh-Hans-CN", want: []string{"zh-hans-cn", "zh-hans", "zh", "en"}},
+		{name: "case3", acceptLanguage: "en;q=0.5, zh_CN, *;q=0.1", want: []string{"zh-cn", "zh", "en"}},
+		{name: "case4", acceptLanguage: "fr;q=0, de-AT;q=0.8, ja", want: []string{"ja", "de-at", "de", "en"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.chain(tt.acceptLanguage); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatalog_Localize(t *testing.T) {
+	c := NewCatalog("en").
+		MustAdd("UserNotFound", "en", "user {{.name}} not found").
+		MustAdd("UserNotFound", "zh", "用户 {{.name}} 不存在").
+		MustAdd("UserNotFound", "zh-TW", "使用者 {{.name}} 不存在").
+		MustAdd("Forbidden", "zh", "无权访问")
+	tests := []struct {
+		name           string
+		err            error
+		acceptLanguage string
+		want           string
+	}{
+		{name: "case1", err: New(404, "UserNotFound", "not found", map[string]any{"name": "tom"}), acceptLanguage: "zh-CN,en;q=0.8", want: "用户 tom 不存在"},
+		{name: "case2", err: New(404, "UserNotFound", "not found", map[string]any{"name": "tom"}), acceptLanguage: "zh-TW", want: "使用者 tom 不存在"},
+		{name: "case3", err: New(404, "UserNotFound", "not found", map[string]any{"name": "tom"}), acceptLanguage: "fr", want: "user tom not found"},
+		{name: "case4", err: New(404, "UserNotFound", "not found", nil), acceptLanguage: "zh", want: "not found"},
+		{name: "case5", err: New(403, "Forbidden", "forbidden", nil), acceptLanguage: "fr", want: "forbidden"},
+		{name: "case6", err: New(403, "Forbidden", "forbidden", nil), acceptLanguage: "zh-CN", want: "无权访问"},
+		{name: "case7", err: New(500, "Unknown", "boom", nil), acceptLanguage: "zh", want: "boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Localize(tt.err, tt.acceptLanguage).GetMessage(); got != tt.want {
+				t.Errorf("Localize().GetMessage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	sentinel := New(403, "Forbidden", "forbidden", nil)
+	m := new(Multi)
+	m.Add(sentinel)
+	m.Add(New(404, "UserNotFound", "not found", map[string]any{"name": "tom"}))
+	if got := c.Localize(m, "zh").GetMessage(); got != "无权访问; 用户 tom 不存在" {
+		t.Errorf("Localize(Multi).GetMessage() = %v", got)
+	}
+	if sentinel.GetMessage() != "forbidden" {
+		t.Errorf("Localize() mutated the error: %v", sentinel)
+	}
+}
+
+func TestWriteHTTP_Localize(t *testing.T) {
+	DefaultCatalog.MustAdd("TestLocalizedQuota", "zh", "配额已用尽：{{.limit}}")
+	err := New(429, "TestLocalizedQuota", "quota exceeded", map[string]any{"limit": 10})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
+	rec := httptest.NewRecorder()
+	WriteHTTP(rec, req, err)
+	if !strings.Contains(rec.Body.String(), `"message":"配额已用尽：10"`) || rec.Header().Get("Content-Language") != "zh" {
+		t.Errorf("WriteHTTP() = %v %s, want the message localized in zh", rec.Header(), rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	WriteProblem(rec, req, err)
+	if !strings.Contains(rec.Body.String(), `"detail":"配额已用尽：10"`) {
+		t.Errorf("WriteProblem() = %s, want the detail localized in zh", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	WriteHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil), err)
+	if !strings.Contains(rec.Body.String(), `"message":"quota exceeded"`) || rec.Header().Get("Content-Language") != "" {
+		t.Errorf("WriteHTTP() = %v %s, want the message kept", rec.Header(), rec.Body.String())
+	}
+}
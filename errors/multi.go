@@ -0,0 +1,215 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+)
+
+var _ ErrorIface = (*Multi)(nil) // make sure Multi implements ErrorIface
+
+// MultiReason is the reason of a Multi holding more than one error.
+const MultiReason = "MultipleErrors"
+
+// AggregateRule computes the code of a Multi from its members, which are never empty.
+type AggregateRule func(errs []ErrorIface) int
+
+// HighestCode is the default AggregateRule: the code with the highest HTTP status is the most severe one, e.g. 5xx over 4xx,
+// whatever its width, so that 500 wins over 40401; among codes of the same status, the highest code wins.
+func HighestCode(errs []ErrorIface) int {
+	code, status := 0, 0
+	for _, e := range errs {
+		c := e.GetCode()
+		if s := HTTPStatus(c); s > status || s == status && c > code {
+			code, status = c, s
+		}
+	}
+	return code
+}
+
+// Multi collects many ErrorIface values, e.g. for batch endpoints and validation.
+// It is safe for concurrent use: errors may be added sequentially by Add, or concurrently by Go and collected by Wait.
+// The zero value is an empty Multi aggregated by HighestCode.
+type Multi struct {
+	mutex sync.Mutex
+	wg    sync.WaitGroup
+	errs  []ErrorIface
+	rule  AggregateRule
+}
+
+// NewMulti returns a pointer to a new empty Multi, computing its code by rule; nil means HighestCode.
+func NewMulti(rule AggregateRule) *Multi {
+	return &Multi{rule: rule}
+}
+
+// Add appends err to the collection; nil is ignored, and any error that is not an ErrorIface is converted through FromError.
+// The members of a Multi are appended, instead of nesting it.
+func (m *Multi) Add(err error) {
+	if err == nil {
+		return
+	}
+	var errs []ErrorIface
+	switch e := err.(type) {
+	case *Multi:
+		errs = e.Errors()
+	case ErrorIface:
+		errs = []ErrorIface{e}
+	default:
+		errs = []ErrorIface{FromError(err)}
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.errs = append(m.errs, errs...)
+}
+
+// Go runs f in a new goroutine and adds its error, or its panic converted by SafeCall; call Wait to wait for all of them.
+func (m *Multi) Go(f func() error) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.Add(SafeCall(f))
+	}()
+}
+
+// Wait waits for all the functions run by Go, and returns ErrorOrNil.
+func (m *Multi) Wait() ErrorIface {
+	m.wg.Wait()
+	return m.ErrorOrNil()
+}
+
+// Len returns the number of errors collected.
+func (m *Multi) Len() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.errs)
+}
+
+// Errors returns a copy of the errors collected.
+func (m *Multi) Errors() []ErrorIface {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	ret := make([]ErrorIface, len(m.errs))
+	copy(ret, m.errs)
+	return ret
+}
+
+// ErrorOrNil returns nil if no error is collected, the only error if there is one, and m otherwise.
+func (m *Multi) ErrorOrNil() ErrorIface {
+	switch errs := m.Errors(); len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return m
+	}
+}
+
+// GetCode returns the code aggregated from the members by the AggregateRule.
+func (m *Multi) GetCode() int {
+	errs := m.Errors()
+	if len(errs) == 0 {
+		return 0
+	}
+	if m.rule == nil {
+		return HighestCode(errs)
+	}
+	return m.rule(errs)
+}
+
+// GetReason returns the reason of the only member, MultiReason otherwise.
+func (m *Multi) GetReason() string {
+	errs := m.Errors()
+	if len(errs) == 1 {
+		return errs[0].GetReason()
+	}
+	return MultiReason
+}
+
+// GetMessage returns the messages of the members joined by "; ".
+func (m *Multi) GetMessage() string {
+	errs := m.Errors()
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.GetMessage()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// GetData returns an empty struct; the members are listed as `errors` by WriteHTTP and MarshalJSON.
+func (m *Multi) GetData() any {
+	return struct{}{}
+}
+
+// GetStack is empty; see the stack of each member.
+func (m *Multi) GetStack() string {
+	return ""
+}
+
+// GetFileLine is empty; see the fileLine of each member.
+func (m *Multi) GetFileLine() string {
+	return ""
+}
+
+// GetExtraDataMap is nil; see the extraDataMap of each member.
+func (m *Multi) GetExtraDataMap() map[string]any {
+	return nil
+}
+
+// Unwrap returns the members; it makes the standard `errors.Is` and `errors.As` traverse all of them.
+func (m *Multi) Unwrap() []error {
+	errs := m.Errors()
+	ret := make([]error, len(errs))
+	for i, e := range errs {
+		ret[i] = e
+	}
+	return ret
+}
+
+// Is reports whether any member matches err.
+func (m *Multi) Is(err error) bool {
+	for _, e := range m.Errors() {
+		if errors.Is(e, err) {
+			return true
+		}
+	}
+	return false
+}
+
+// Equal compares code and message.
+func (m *Multi) Equal(err error) bool {
+	fe := FromError(err)
+	return m.GetCode() == fe.GetCode() && m.GetMessage() == fe.GetMessage()
+}
+
+// Error joins the members' errors by newlines; it implements standard `error` interface.
+func (m *Multi) Error() string {
+	errs := m.Errors()
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// MarshalJSON encodes the Multi as its aggregate code, reason and message, followed by the list of members in the wire format.
+func (m *Multi) MarshalJSON() ([]byte, error) {
+	return json.Marshal(multiToWire(m, &marshalConfig{}))
+}
+
+func multiToWire(m *Multi, cfg *marshalConfig) *wireError {
+	errs := m.Errors()
+	w := &wireError{
+		Version: WireVersion,
+		Code:    m.GetCode(),
+		Reason:  m.GetReason(),
+		Message: m.GetMessage(),
+		Errors:  make([]*wireError, len(errs)),
+	}
+	for i, e := range errs {
+		w.Errors[i] = toWire(errorOf(e), cfg)
+	}
+	return w
+}
@@ -0,0 +1,156 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMulti_GetCode(t *testing.T) {
+	lowest := func(errs []ErrorIface) int {
+		code := errs[0].GetCode()
+		for _, e := range errs[1:] {
+			if e.GetCode() < code {
+				code = e.GetCode()
+			}
+		}
+		return code
+	}
+	tests := []struct {
+		name string
+		rule AggregateRule
+		errs []error
+		want int
+	}{
+		{name: "case1", rule: nil, errs: nil, want: 0},
+		{name: "case2", rule: nil, errs: []error{New(400, "Bad", "", nil), New(503, "Unavailable", "", nil), New(404, "NotFound", "", nil)}, want: 503},
+		{name: "case3", rule: lowest, errs: []error{New(400, "Bad", "", nil), New(503, "Unavailable", "", nil), New(404, "NotFound", "", nil)}, want: 400},
+		{name: "case4", rule: nil, errs: []error{nil, errors.New("eof")}, want: 500},
+		{name: "case5", rule: nil, errs: []error{errors.New("eof"), New(40401, "NotFound", "", nil)}, want: 500},
+		{name: "case6", rule: nil, errs: []error{New(40401, "NotFound", "", nil), New(503, "Unavailable", "", nil), New(50001, "Internal", "", nil)}, want: 503},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMulti(tt.rule)
+			for _, err := range tt.errs {
+				m.Add(err)
+			}
+			if got := m.GetCode(); got != tt.want {
+				t.Errorf("GetCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMulti_ErrorOrNil(t *testing.T) {
+	m := new(Multi)
+	if err := m.ErrorOrNil(); err != nil {
+		t.Errorf("ErrorOrNil() = %v, want nil", err)
+	}
+	e := New(400, "Bad", "", nil)
+	m.Add(e)
+	if err := m.ErrorOrNil(); err != e {
+		t.Errorf("ErrorOrNil() = %v, want %v", err, e)
+	}
+	m.Add(New(404, "NotFound", "", nil))
+	if err := m.ErrorOrNil(); err != m {
+		t.Errorf("ErrorOrNil() = %v, want the Multi", err)
+	}
+	if got := m.GetReason(); got != MultiReason {
+		t.Errorf("GetReason() = %v, want %v", got, MultiReason)
+	}
+}
+
+func TestMulti_Go(t *testing.T) {
+	m := new(Multi)
+	for i := 0; i < 50; i++ {
+		i := i
+		m.Go(func() error {
+			if i%2 == 0 {
+				return nil
+			}
+			return New(400+i, "Bad", "", nil)
+		})
+	}
+	if err := m.Wait(); err == nil || m.Len() != 25 || err.GetCode() != 449 {
+		t.Errorf("Wait() = %v with %d errors, want 25 errors with code 449", err, m.Len())
+	}
+}
+
+func TestMulti_IsAs(t *testing.T) {
+	notFound := Define(404, "NotFound", "not found")
+	inner := &Error{code: 503, reason: "Unavailable"}
+	m := new(Multi)
+	m.Add(New(400, "Bad", "", nil))
+	m.Add(Wrap(inner, 500, "Internal", ""))
+	m.Add(notFound.New())
+
+	nested := new(Multi)
+	nested.Add(m)
+	if nested.Len() != 3 {
+		t.Errorf("Add() nested a Multi: Len() = %d, want 3", nested.Len())
+	}
+
+	tests := []struct {
+		name   string
+		target error
+		want   bool
+	}{
+		{name: "case1", target: notFound, want: true},
+		{name: "case2", target: &Error{reason: "Unavailable"}, want: true},
+		{name: "case3", target: &Error{reason: "Conflict"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Is(nested, tt.target); got != tt.want {
+				t.Errorf("Is() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var target *Error
+	if !As(m, &target) || target.GetReason() != "Bad" {
+		t.Errorf("As() = %v, want the first member", target)
+	}
+}
+
+func TestMulti_MarshalJSON(t *testing.T) {
+	m := new(Multi)
+	m.Add(&Error{code: 400, reason: "Bad", message: "a", data: struct{}{}})
+	m.Add(&Error{code: 404, reason: "NotFound", message: "b", data: struct{}{}})
+
+	want := `{"v":1,"code":404,"reason":"MultipleErrors","message":"a; b","errors":[{"v":1,"code":400,"reason":"Bad","message":"a"},{"v":1,"code":404,"reason":"NotFound","message":"b"}]}`
+	got, err := json.Marshal(m)
+	if err != nil || string(got) != want {
+		t.Errorf("json.Marshal() = %s, %v, want %s", got, err, want)
+	}
+	if got, err := Marshal(m); err != nil || string(got) != want {
+		t.Errorf("Marshal() = %s, %v, want %s", got, err, want)
+	}
+
+	back, err := Unmarshal(got)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if bm, ok := back.(*Multi); !ok || bm.Len() != 2 || bm.GetCode() != 404 {
+		t.Errorf("Unmarshal() = %#v, want a Multi of 2 errors", back)
+	}
+}
+
+func TestWriteHTTP_Multi(t *testing.T) {
+	m := new(Multi)
+	m.Add(&Error{code: 40001, reason: "Bad", message: "a", data: struct{}{}})
+	m.Add(&Error{code: 40401, reason: "NotFound", message: "b", data: struct{}{}})
+
+	rec := httptest.NewRecorder()
+	WriteHTTP(rec, httptest.NewRequest(http.MethodPost, "/batch", nil), m)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("WriteHTTP() status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	want := `{"code":40401,"reason":"MultipleErrors","message":"a; b","data":{},"errors":[{"code":40001,"reason":"Bad","message":"a","data":{}},{"code":40401,"reason":"NotFound","message":"b","data":{}}]}` + "\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("WriteHTTP() body = %s, want %s", got, want)
+	}
+}
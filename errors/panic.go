@@ -0,0 +1,115 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Code and reason of the ErrorIface converted from a recovered panic.
+const (
+	PanicCode   = 500
+	PanicReason = "Panic"
+)
+
+// PanicKey is the key of the extra data holding the recovered panic value; like the internal message, it is never rendered by ToProblem and Marshal.
+const PanicKey = "panic"
+
+// PanicMessage is the public message of the ErrorIface converted from a recovered panic; the panic value is in its internal message.
+var PanicMessage = "internal error"
+
+// Recover converts a recovered panic into an error stored in *errp, see FromPanic.
+// It MUST be deferred directly, e.g. `defer errors.Recover(&err)` with err a named result, or it recovers nothing.
+func Recover(errp *error) {
+	if r := recover(); r != nil {
+		*errp = fromPanic(r)
+	}
+}
+
+// SafeCall calls fn and returns its error converted through FromError, or the error converted from its panic, see FromPanic.
+func SafeCall(fn func() error) (ret ErrorIface) {
+	defer func() {
+		if r := recover(); r != nil {
+			ret = fromPanic(r)
+		}
+	}()
+	if err := fn(); err != nil {
+		return FromError(err)
+	}
+	return nil
+}
+
+// FromPanic converts a value returned by recover into an error with code PanicCode, reason PanicReason and message PanicMessage,
+// holding the value as extra data under PanicKey, in its internal message, and as its cause if it is an error.
+// Its stack and fileLine are captured at the panic site, without the frames of the runtime.
+// It MUST be called while panicking, i.e. from a deferred function, and returns nil if r is nil.
+func FromPanic(r any) ErrorIface {
+	if r == nil {
+		return nil
+	}
+	return fromPanic(r)
+}
+
+// fromPanic is FromPanic for its callers in this package, which are all deferred functions.
+func fromPanic(r any) *Error {
+	e := &Error{
+		code:         PanicCode,
+		reason:       PanicReason,
+		message:      PanicMessage,
+		internal:     fmt.Sprint("panic: ", r),
+		data:         struct{}{},
+		extraDataMap: map[string]any{PanicKey: r},
+	}
+	if cause, ok := r.(error); ok {
+		e.cause = cause
+	}
+
+	depth := int(stackDepth.Load())
+	pcs := make([]uintptr, depth+32)
+	pcs = panicSite(pcs[:runtime.Callers(2, pcs)])
+	if len(pcs) > 0 {
+		e.pcs, e.stackDepth = pcs, depth
+		if frames := e.Frames(); len(frames) > 0 {
+			e.fileLine = fmt.Sprintf("%s:%d", frames[0].File, frames[0].Line)
+		}
+	}
+	return e
+}
+
+// panicSite drops the frames above the panic site from pcs, i.e. the deferred functions and the runtime panic machinery;
+// it returns nil if pcs is not captured while panicking.
+func panicSite(pcs []uintptr) []uintptr {
+	i := 0
+	for ; i < len(pcs) && funcName(pcs[i]) != "runtime.gopanic"; i++ {
+	}
+	// runtime.panicmem、runtime.sigpanic、runtime.goPanicIndex 等
+	for ; i < len(pcs) && strings.HasPrefix(funcName(pcs[i]), "runtime."); i++ {
+	}
+	if i == len(pcs) {
+		return nil
+	}
+	return pcs[i:]
+}
+
+// funcName returns the name of the innermost function at pc, as resolved by runtime.CallersFrames.
+func funcName(pc uintptr) string {
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return f.Function
+}
+
+// publicExtraData returns m without the panic value, which may carry internal details, e.g. addresses or credentials.
+func publicExtraData(m map[string]any) map[string]any {
+	if _, ok := m[PanicKey]; !ok {
+		return m
+	}
+	ret := make(map[string]any, len(m)-1)
+	for k, v := range m {
+		if k != PanicKey {
+			ret[k] = v
+		}
+	}
+	if len(ret) == 0 {
+		return nil
+	}
+	return ret
+}
@@ -0,0 +1,229 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem documents.
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypeBase is the prefix of the problem type URIs, joined with the reason, e.g. "https://errors.example.com/".
+// Set it at init; a problem without reason is typed as "about:blank".
+var ProblemTypeBase = ""
+
+// problemMembers are the members defined by RFC 7807, which extension members must not override.
+var problemMembers = map[string]bool{"type": true, "title": true, "status": true, "detail": true, "instance": true}
+
+// Problem is an RFC 7807 problem document.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any // extension members, flattened into the document
+}
+
+// MarshalJSON flattens the extension members into the document.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		if !problemMembers[k] {
+			m[k] = v
+		}
+	}
+	m["type"] = p.Type
+	m["title"] = p.Title
+	m["status"] = p.Status
+	if p.Detail != "" {
+		m["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		m["instance"] = p.Instance
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON collects every non-standard member into Extensions.
+func (p *Problem) UnmarshalJSON(b []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+
+	*p = Problem{Type: "about:blank"}
+	for k, raw := range m {
+		var err error
+		switch k {
+		case "type":
+			err = json.Unmarshal(raw, &p.Type)
+		case "title":
+			err = json.Unmarshal(raw, &p.Title)
+		case "status":
+			err = json.Unmarshal(raw, &p.Status)
+		case "detail":
+			err = json.Unmarshal(raw, &p.Detail)
+		case "instance":
+			err = json.Unmarshal(raw, &p.Instance)
+		default:
+			var v any
+			if err = json.Unmarshal(raw, &v); err == nil {
+				if p.Extensions == nil {
+					p.Extensions = map[string]any{}
+				}
+				p.Extensions[k] = v
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("problem member %q: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// ToProblem renders err as a problem document: reason maps to type, message to detail,
+// and extraDataMap but the panic value to extension members, along with `code`, `data` if any, and `errors` listing the members of a Multi.
+// Stack and fileLine are added in debug mode. Any error that is not an ErrorIface is converted through FromError.
+func ToProblem(err error) Problem {
+	e, ok := err.(ErrorIface)
+	if !ok {
+		e = FromError(err)
+	}
+
+	status := HTTPStatus(e.GetCode())
+	p := Problem{
+		Type:       "about:blank",
+		Title:      http.StatusText(status),
+		Status:     status,
+		Detail:     e.GetMessage(),
+		Extensions: map[string]any{},
+	}
+	if reason := e.GetReason(); reason != "" {
+		p.Type = ProblemTypeBase + reason
+	}
+
+	for k, v := range publicExtraData(e.GetExtraDataMap()) {
+		p.Extensions[k] = v
+	}
+	p.Extensions["code"] = e.GetCode()
+	if data := e.GetData(); data != nil && data != (struct{}{}) {
+		p.Extensions["data"] = data
+	}
+	if debugMode.Load() {
+		p.Extensions["stack"] = e.GetStack()
+		p.Extensions["file"] = e.GetFileLine()
+	}
+	if m, ok := e.(*Multi); ok {
+		p.Extensions["errors"] = NewHTTPBody(m).Errors
+	}
+	return p
+}
+
+// WriteProblem writes err to w as an RFC 7807 problem document, with the request URI as instance.
+// The detail is localized by DefaultCatalog following the `Accept-Language` header of r, see Localize.
+// The `Retry-After` header is set if err declares it, see WithRetryAfter.
+// Nothing is written if err is nil.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+	err = localizeRequest(w, r, err)
+	p := ToProblem(err)
+	if r != nil {
+		p.Instance = r.URL.RequestURI()
+	}
+
+	b, jerr := json.Marshal(p)
+	if jerr != nil {
+		// 扩展字段无法序列化时只输出标准字段
+		p.Extensions = nil
+		b, _ = json.Marshal(p)
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	setRetryAfter(w.Header(), err)
+	w.WriteHeader(p.Status)
+	if r != nil && r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(append(b, '\n'))
+}
+
+// ParseProblem parses a problem document returned by another service back into an ErrorIface.
+// The reason is the type without ProblemTypeBase, or the last segment of a foreign type URI;
+// the code is the `code` extension member if any, the status otherwise.
+func ParseProblem(b []byte) (ErrorIface, error) {
+	var p Problem
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, err
+	}
+	return p.ToError(), nil
+}
+
+// ParseProblemResponse parses the body of resp if it is a problem document; resp.Body is consumed but not closed.
+// The `Retry-After` header, if any, is kept as by WithRetryAfter.
+func ParseProblemResponse(resp *http.Response) (ErrorIface, error) {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != ProblemContentType {
+		return nil, fmt.Errorf("unexpected content type %q", mediaType)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	e, err := ParseProblem(b)
+	if err != nil {
+		return nil, err
+	}
+	if d := parseRetryAfter(resp.Header); d > 0 {
+		if se, ok := e.(*Error); ok {
+			WithRetryAfter(d)(se)
+		}
+	}
+	return e, nil
+}
+
+// ToError converts the problem document into an ErrorIface.
+func (p Problem) ToError() ErrorIface {
+	code := p.Status
+	var data any
+	extra := map[string]any{}
+	for k, v := range p.Extensions {
+		switch k {
+		case "code":
+			if c, ok := v.(float64); ok {
+				code = int(c)
+			}
+		case "data":
+			data = v
+		default:
+			extra[k] = v
+		}
+	}
+
+	var options []Option
+	if len(extra) > 0 {
+		options = append(options, WithExtraDataMap(extra))
+	}
+	return NewWithSkip(2, code, problemReason(p.Type), p.Detail, data, options...)
+}
+
+// problemReason extracts the reason from a problem type.
+func problemReason(typ string) string {
+	switch {
+	case typ == "" || typ == "about:blank":
+		return ""
+	case ProblemTypeBase != "" && strings.HasPrefix(typ, ProblemTypeBase):
+		return strings.TrimPrefix(typ, ProblemTypeBase)
+	}
+	if i := strings.LastIndexAny(typ, "/#"); i >= 0 {
+		return typ[i+1:]
+	}
+	return typ
+}
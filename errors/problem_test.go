@@ -0,0 +1,84 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteProblem(t *testing.T) {
+	ProblemTypeBase = "https://errors.example.com/"
+	defer func() { ProblemTypeBase = "" }()
+
+	err := New(40401, "UserNotFound", "user alice not found", nil, WithExtraData("user", "alice"), WithExtraData("status", "ignored"))
+	w := httptest.NewRecorder()
+	WriteProblem(w, httptest.NewRequest(http.MethodGet, "/users/alice", nil), err)
+
+	if w.Code != 404 || w.Header().Get("Content-Type") != ProblemContentType {
+		t.Errorf("WriteProblem() status = %v, content type = %v", w.Code, w.Header().Get("Content-Type"))
+	}
+	want := map[string]any{
+		"type":     "https://errors.example.com/UserNotFound",
+		"title":    "Not Found",
+		"status":   404.0,
+		"detail":   "user alice not found",
+		"instance": "/users/alice",
+		"code":     40401.0,
+		"user":     "alice",
+	}
+	var got map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("WriteProblem() body = %s, want %v", w.Body.Bytes(), want)
+	}
+}
+
+func TestParseProblem(t *testing.T) {
+	ProblemTypeBase = "https://errors.example.com/"
+	defer func() { ProblemTypeBase = "" }()
+
+	tests := []struct {
+		name      string
+		doc       string
+		want      *Error
+		wantExtra map[string]any
+	}{
+		{name: "case1", doc: `{"type":"https://errors.example.com/UserNotFound","title":"Not Found","status":404,"detail":"user alice not found","code":40401,"user":"alice"}`,
+			want: &Error{code: 40401, reason: "UserNotFound", message: "user alice not found"}, wantExtra: map[string]any{"user": "alice"}},
+		{name: "case2", doc: `{"type":"https://other.example.org/probs/out-of-credit","title":"You do not have enough credit.","status":403,"detail":"balance is 30"}`,
+			want: &Error{code: 403, reason: "out-of-credit", message: "balance is 30"}},
+		{name: "case3", doc: `{"status":503}`,
+			want: &Error{code: 503}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseProblem([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("ParseProblem() error = %v", err)
+			}
+			if !got.Equal(tt.want) || got.GetReason() != tt.want.reason {
+				t.Errorf("ParseProblem() = %v, want %v", got, tt.want)
+			}
+			if len(tt.wantExtra) > 0 && !reflect.DeepEqual(got.GetExtraDataMap(), tt.wantExtra) {
+				t.Errorf("ParseProblem() extraDataMap = %v, want %v", got.GetExtraDataMap(), tt.wantExtra)
+			}
+		})
+	}
+}
+
+func TestParseProblemResponse(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteProblem(w, httptest.NewRequest(http.MethodGet, "/", nil), New(50301, "StockUnavailable", "try later", nil))
+
+	got, err := ParseProblemResponse(w.Result())
+	if err != nil || got.GetCode() != 50301 || got.GetReason() != "StockUnavailable" {
+		t.Errorf("ParseProblemResponse() = %v, %v", got, err)
+	}
+
+	resp := &http.Response{Header: http.Header{"Content-Type": {"text/html"}}, Body: http.NoBody}
+	if _, err := ParseProblemResponse(resp); err == nil || !strings.Contains(err.Error(), "content type") {
+		t.Errorf("ParseProblemResponse() error = %v, want content type error", err)
+	}
+}
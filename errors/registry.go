@@ -0,0 +1,143 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Entry is an error code declared in a Registry.
+type Entry struct {
+	Code       int    `json:"code"`
+	Reason     string `json:"reason"`
+	Message    string `json:"message"`
+	Service    string `json:"service"`
+	HTTPStatus int    `json:"http_status,omitempty"`
+}
+
+// Registry is a catalog of error codes and reasons, declared once by each service.
+// Codes and reasons are unique across the whole catalog.
+type Registry struct {
+	mutex    *sync.RWMutex
+	entries  []Entry
+	byCode   map[int]int    // index in entries
+	byReason map[string]int // index in entries
+}
+
+// DefaultRegistry is the registry used by the package-level Register and MustRegister.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns a pointer to a new empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		mutex:    new(sync.RWMutex),
+		byCode:   map[int]int{},
+		byReason: map[string]int{},
+	}
+}
+
+// Register declares code and reason for service into DefaultRegistry.
+func Register(service string, code int, reason, message string) error {
+	return DefaultRegistry.Register(service, code, reason, message)
+}
+
+// MustRegister is like Register but panics on duplicates; use it to declare codes at init.
+func MustRegister(service string, code int, reason, message string) {
+	DefaultRegistry.MustRegister(service, code, reason, message)
+}
+
+// Register declares code and reason for service, with message as the default message.
+// It returns an error if the code or the reason is already declared.
+func (r *Registry) Register(service string, code int, reason, message string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if i, ok := r.byCode[code]; ok {
+		return fmt.Errorf("duplicate error code %d: reason %q by %q conflicts with reason %q by %q",
+			code, reason, service, r.entries[i].Reason, r.entries[i].Service)
+	}
+	if i, ok := r.byReason[reason]; ok {
+		return fmt.Errorf("duplicate error reason %q: code %d by %q conflicts with code %d by %q",
+			reason, code, service, r.entries[i].Code, r.entries[i].Service)
+	}
+
+	r.entries = append(r.entries, Entry{Code: code, Reason: reason, Message: message, Service: service})
+	r.byCode[code] = len(r.entries) - 1
+	r.byReason[reason] = len(r.entries) - 1
+	return nil
+}
+
+// MustRegister is like Register but panics on duplicates; use it to declare codes at init.
+func (r *Registry) MustRegister(service string, code int, reason, message string) {
+	if err := r.Register(service, code, reason, message); err != nil {
+		panic(err)
+	}
+}
+
+// setHTTPStatus records the HTTP status of the entry declared with code.
+func (r *Registry) setHTTPStatus(code, status int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if i, ok := r.byCode[code]; ok {
+		r.entries[i].HTTPStatus = status
+	}
+}
+
+// Lookup returns the entry declared with code.
+func (r *Registry) Lookup(code int) (Entry, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if i, ok := r.byCode[code]; ok {
+		return r.entries[i], true
+	}
+	return Entry{}, false
+}
+
+// LookupReason returns the entry declared with reason.
+func (r *Registry) LookupReason(reason string) (Entry, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if i, ok := r.byReason[reason]; ok {
+		return r.entries[i], true
+	}
+	return Entry{}, false
+}
+
+// Entries returns all entries sorted by code.
+func (r *Registry) Entries() []Entry {
+	r.mutex.RLock()
+	ret := make([]Entry, len(r.entries))
+	copy(ret, r.entries)
+	r.mutex.RUnlock()
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Code < ret[j].Code })
+	return ret
+}
+
+// JSON exports the catalog as a JSON array sorted by code, e.g. for API docs.
+func (r *Registry) JSON() ([]byte, error) {
+	return json.MarshalIndent(r.Entries(), "", "  ")
+}
+
+// Markdown exports the catalog as a Markdown table sorted by code, e.g. for front-end teams.
+func (r *Registry) Markdown() string {
+	var b strings.Builder
+	b.WriteString("| Code | Reason | Service | Message | HTTP Status |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, e := range r.Entries() {
+		status := ""
+		if e.HTTPStatus != 0 {
+			status = strconv.Itoa(e.HTTPStatus)
+		}
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | %s |\n", e.Code, markdownEscape(e.Reason), markdownEscape(e.Service), markdownEscape(e.Message), status)
+	}
+	return b.String()
+}
+
+// markdownEscape keeps s within a single table cell.
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(s)
+}
@@ -0,0 +1,73 @@
+package errors
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	type args struct {
+		service string
+		code    int
+		reason  string
+		message string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{name: "case1", args: args{service: "user", code: 40401, reason: "UserNotFound", message: "user not found"}, wantErr: false},
+		{name: "case2", args: args{service: "order", code: 40401, reason: "OrderNotFound", message: "order not found"}, wantErr: true},
+		{name: "case3", args: args{service: "order", code: 40402, reason: "UserNotFound", message: "order not found"}, wantErr: true},
+		{name: "case4", args: args{service: "order", code: 40402, reason: "OrderNotFound", message: "order not found"}, wantErr: false},
+	}
+	r := NewRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := r.Register(tt.args.service, tt.args.code, tt.args.reason, tt.args.message); (err != nil) != tt.wantErr {
+				t.Errorf("Registry.Register() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistry_MustRegister(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister("user", 40401, "UserNotFound", "")
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Registry.MustRegister() did not panic on duplicate code")
+		}
+	}()
+	r.MustRegister("user", 40401, "Other", "")
+}
+
+func TestRegistry_Export(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister("order", 50301, "StockUnavailable", "stock | inventory down")
+	r.Define("user", 40401, "UserNotFound", "user not found").WithHTTPStatus(404)
+
+	want := []Entry{
+		{Code: 40401, Reason: "UserNotFound", Message: "user not found", Service: "user", HTTPStatus: 404},
+		{Code: 50301, Reason: "StockUnavailable", Message: "stock | inventory down", Service: "order"},
+	}
+	b, err := r.JSON()
+	if err != nil {
+		t.Fatalf("Registry.JSON() error = %v", err)
+	}
+	var got []Entry
+	if err := json.Unmarshal(b, &got); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Registry.JSON() = %s, want %v", b, want)
+	}
+
+	md := r.Markdown()
+	if !strings.Contains(md, "| 40401 | UserNotFound | user | user not found | 404 |\n") || !strings.Contains(md, `stock \| inventory down`) {
+		t.Errorf("Registry.Markdown() = %v", md)
+	}
+	if strings.Index(md, "40401") > strings.Index(md, "50301") {
+		t.Errorf("Registry.Markdown() is not sorted by code: %v", md)
+	}
+}
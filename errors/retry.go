@@ -0,0 +1,258 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	_ RetryIface = (*Error)(nil) // make sure Error implements RetryIface
+	_ RetryIface = (*Multi)(nil) // make sure Multi implements RetryIface
+)
+
+// RetryIface is implemented by errors carrying retry metadata, see WithRetryable, WithBackoff and WithRetryAfter.
+type RetryIface interface {
+	// IsRetryable reports whether the failed call may be retried; ok is false if it is not declared.
+	IsRetryable() (retryable, ok bool)
+	// GetBackoff returns the suggested initial backoff, 0 if not declared.
+	GetBackoff() time.Duration
+	// GetRetryAfter returns the minimum delay before retrying, 0 if not declared.
+	GetRetryAfter() time.Duration
+}
+
+// retryMeta is the retry metadata of an Error.
+type retryMeta struct {
+	retryable    bool
+	retryableSet bool
+	backoff      time.Duration
+	retryAfter   time.Duration
+}
+
+// IsRetryable ...
+func (e *Error) IsRetryable() (retryable, ok bool) {
+	if e == nil || e.retry == nil {
+		return false, false
+	}
+	return e.retry.retryable, e.retry.retryableSet
+}
+
+// GetBackoff ...
+func (e *Error) GetBackoff() time.Duration {
+	if e == nil || e.retry == nil {
+		return 0
+	}
+	return e.retry.backoff
+}
+
+// GetRetryAfter ...
+func (e *Error) GetRetryAfter() time.Duration {
+	if e == nil || e.retry == nil {
+		return 0
+	}
+	return e.retry.retryAfter
+}
+
+// withRetryMeta returns an Option updating a copy of the retry metadata of the error.
+func withRetryMeta(update func(*retryMeta)) Option {
+	return func(err *Error) {
+		var m retryMeta
+		if err.retry != nil {
+			m = *err.retry
+		}
+		update(&m)
+		err.retry = &m
+	}
+}
+
+// WithRetryable declares whether the failed call may be retried.
+func WithRetryable(retryable bool) Option {
+	return withRetryMeta(func(m *retryMeta) {
+		m.retryable, m.retryableSet = retryable, true
+	})
+}
+
+// WithBackoff suggests the initial backoff to retry the failed call with; it implies WithRetryable(true).
+func WithBackoff(d time.Duration) Option {
+	return withRetryMeta(func(m *retryMeta) {
+		m.retryable, m.retryableSet = true, true
+		m.backoff = d
+	})
+}
+
+// WithRetryAfter sets the minimum delay before retrying, e.g. from a `Retry-After` header; it implies WithRetryable(true).
+// WriteHTTP and WriteProblem write it as the `Retry-After` header.
+func WithRetryAfter(d time.Duration) Option {
+	return withRetryMeta(func(m *retryMeta) {
+		m.retryable, m.retryableSet = true, true
+		m.retryAfter = d
+	})
+}
+
+// IsRetryable reports whether the failed call may be retried, only if every member declares so.
+func (m *Multi) IsRetryable() (retryable, ok bool) {
+	errs := m.Errors()
+	if len(errs) == 0 {
+		return false, false
+	}
+	for _, e := range errs {
+		if !IsRetryable(e) {
+			return false, true
+		}
+	}
+	return true, true
+}
+
+// GetBackoff returns the highest backoff of the members.
+func (m *Multi) GetBackoff() time.Duration {
+	var d time.Duration
+	for _, e := range m.Errors() {
+		if r, ok := e.(RetryIface); ok && r.GetBackoff() > d {
+			d = r.GetBackoff()
+		}
+	}
+	return d
+}
+
+// GetRetryAfter returns the highest Retry-After of the members.
+func (m *Multi) GetRetryAfter() time.Duration {
+	var d time.Duration
+	for _, e := range m.Errors() {
+		if r, ok := e.(RetryIface); ok && r.GetRetryAfter() > d {
+			d = r.GetRetryAfter()
+		}
+	}
+	return d
+}
+
+// IsRetryable reports whether the call failed with err may be retried.
+// The first error in err's chain declaring it, see RetryIface, decides;
+// otherwise timeout and temporary errors of the standard library, e.g. net.Error, are retryable.
+// A canceled context is never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if r := retryOf(err); r != nil {
+		retryable, _ := r.IsRetryable()
+		return retryable
+	}
+
+	if t := (interface{ Timeout() bool })(nil); errors.As(err, &t) && t.Timeout() {
+		return true
+	}
+	if t := (interface{ Temporary() bool })(nil); errors.As(err, &t) && t.Temporary() {
+		return true
+	}
+	return false
+}
+
+// retryOf returns the first error in err's chain declaring whether it is retryable, nil if none.
+func retryOf(err error) RetryIface {
+	for chain := []error{err}; len(chain) > 0; chain = chain[1:] {
+		if r, ok := chain[0].(RetryIface); ok {
+			if _, ok := r.IsRetryable(); ok {
+				return r
+			}
+		}
+		switch u := chain[0].(type) {
+		case interface{ Unwrap() error }:
+			if next := u.Unwrap(); next != nil {
+				chain = append(chain, next)
+			}
+		case interface{ Unwrap() []error }:
+			chain = append(chain, u.Unwrap()...)
+		}
+	}
+	return nil
+}
+
+// setRetryAfter sets the `Retry-After` header, in seconds, if err declares it.
+func setRetryAfter(h http.Header, err error) {
+	if r := retryOf(err); r != nil && r.GetRetryAfter() > 0 {
+		h.Set("Retry-After", strconv.Itoa(int(math.Ceil(r.GetRetryAfter().Seconds()))))
+	}
+}
+
+// parseRetryAfter parses a `Retry-After` header, in seconds or as an HTTP date; 0 if absent or invalid.
+func parseRetryAfter(h http.Header) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && time.Until(t) > 0 {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// RetryPolicy configures Retry.
+type RetryPolicy struct {
+	MaxAttempts    int           // max number of calls, including the first one; 0 means 1
+	InitialBackoff time.Duration // delay after the first failure, unless the error suggests another
+	MaxBackoff     time.Duration // cap of the delay, 0 for uncapped; Retry-After is not capped
+	Multiplier     float64       // growth of the delay after each failure; less than 1 means 1
+	Jitter         float64       // randomization of the delay, e.g. 0.2 for ±20%
+}
+
+// DefaultRetryPolicy ...
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// backoff returns the delay before the attempt-th retry, starting from 1, after the call failed with err.
+func (p RetryPolicy) backoff(attempt int, err error) time.Duration {
+	d := p.InitialBackoff
+	r := retryOf(err)
+	if r != nil && r.GetBackoff() > 0 {
+		d = r.GetBackoff()
+	}
+
+	f := float64(d) * math.Pow(math.Max(p.Multiplier, 1), float64(attempt-1))
+	if p.MaxBackoff > 0 && f > float64(p.MaxBackoff) {
+		f = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		f *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	d = time.Duration(f)
+
+	if r != nil && r.GetRetryAfter() > d {
+		d = r.GetRetryAfter()
+	}
+	return d
+}
+
+// Retry calls fn until it succeeds, fails with an error that is not retryable as reported by IsRetryable,
+// or policy.MaxAttempts is reached, waiting with exponential backoff and jitter between calls.
+// It returns the last error of fn, or the error of ctx if ctx is done before.
+func Retry(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := fn()
+		if err == nil || attempt >= policy.MaxAttempts || !IsRetryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(policy.backoff(attempt, err))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "case1", err: nil, want: false},
+		{name: "case2", err: New(503, "Unavailable", "", nil, WithRetryable(true)), want: true},
+		{name: "case3", err: New(503, "Unavailable", "", nil, WithRetryable(false)), want: false},
+		{name: "case4", err: New(500, "Internal", "", nil), want: false},
+		{name: "case5", err: Wrap(New(503, "Unavailable", "", nil, WithBackoff(time.Second)), 500, "Internal", ""), want: true},
+		{name: "case6", err: fmt.Errorf("read: %w", os.ErrDeadlineExceeded), want: true},
+		{name: "case7", err: context.DeadlineExceeded, want: true},
+		{name: "case8", err: context.Canceled, want: false},
+		{name: "case9", err: Wrap(os.ErrDeadlineExceeded, 500, "Internal", "", WithRetryable(false)), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	tests := []struct {
+		name    string
+		attempt int
+		err     error
+		want    time.Duration
+	}{
+		{name: "case1", attempt: 1, err: New(503, "", "", nil), want: 100 * time.Millisecond},
+		{name: "case2", attempt: 3, err: New(503, "", "", nil), want: 400 * time.Millisecond},
+		{name: "case3", attempt: 10, err: New(503, "", "", nil), want: time.Second},
+		{name: "case4", attempt: 2, err: New(503, "", "", nil, WithBackoff(time.Millisecond)), want: 2 * time.Millisecond},
+		{name: "case5", attempt: 1, err: New(429, "", "", nil, WithRetryAfter(5*time.Second)), want: 5 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.backoff(tt.attempt, tt.err); got != tt.want {
+				t.Errorf("backoff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 2, Jitter: 0.5}
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "case1", errs: []error{nil}, wantCalls: 1, wantErr: false},
+		{name: "case2", errs: []error{New(503, "", "", nil, WithRetryable(true)), nil}, wantCalls: 2, wantErr: false},
+		{name: "case3", errs: []error{New(400, "", "", nil)}, wantCalls: 1, wantErr: true},
+		{name: "case4", errs: []error{os.ErrDeadlineExceeded, os.ErrDeadlineExceeded, os.ErrDeadlineExceeded, nil}, wantCalls: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := Retry(context.Background(), policy, func() error {
+				calls++
+				return tt.errs[calls-1]
+			})
+			if calls != tt.wantCalls || (err != nil) != tt.wantErr {
+				t.Errorf("Retry() = %v after %d calls, want %d calls and error %v", err, calls, tt.wantCalls, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetry_Context(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour}
+	err := Retry(ctx, policy, func() error {
+		return New(503, "", "", nil, WithRetryable(true))
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Retry() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRetryAfter_HTTP(t *testing.T) {
+	err := New(503, "Unavailable", "try later", nil, WithRetryAfter(1500*time.Millisecond))
+
+	rec := httptest.NewRecorder()
+	WriteHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil), err)
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("WriteHTTP() Retry-After = %q, want %q", got, "2")
+	}
+
+	rec = httptest.NewRecorder()
+	WriteProblem(rec, httptest.NewRequest(http.MethodGet, "/", nil), err)
+	resp := rec.Result()
+	parsed, perr := ParseProblemResponse(resp)
+	if perr != nil {
+		t.Fatalf("ParseProblemResponse() error = %v", perr)
+	}
+	if r, ok := parsed.(RetryIface); !ok || r.GetRetryAfter() != 2*time.Second || !IsRetryable(parsed) {
+		t.Errorf("ParseProblemResponse() = %v, want Retry-After 2s", parsed)
+	}
+}
@@ -0,0 +1,132 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+var _ fmt.Formatter = (*Error)(nil) // make sure Error implements fmt.Formatter
+
+// stackDepth is the default number of frames captured by WithStack.
+var stackDepth atomic.Int32
+
+func init() {
+	stackDepth.Store(32)
+}
+
+// pkgPrefix is the prefix of the function names of this package.
+var pkgPrefix = reflect.TypeOf(Error{}).PkgPath() + "."
+
+// pkgDir is the source directory of this package, whose frames are trimmed from the top of the stack.
+var pkgDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// internalFrame reports whether f belongs to this package's own code, i.e. not its tests.
+func internalFrame(f runtime.Frame) bool {
+	return strings.HasPrefix(f.Function, pkgPrefix) && filepath.Dir(f.File) == pkgDir && !strings.HasSuffix(f.File, "_test.go")
+}
+
+// Frame is a resolved stack frame.
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// String returns the frame in the same layout as runtime.Stack.
+func (f Frame) String() string {
+	return fmt.Sprintf("%s\n\t%s:%d", f.Function, f.File, f.Line)
+}
+
+// SetStackDepth sets the default number of frames captured by WithStack and WithStackTrace.
+func SetStackDepth(depth int) {
+	stackDepth.Store(int32(depth))
+}
+
+// WithStack captures the program counters of the current stack into the error; it is cheap,
+// the frames are only resolved when needed, i.e. by Frames, GetStack and the %+v verb.
+func WithStack() Option {
+	return WithStackDepth(int(stackDepth.Load()))
+}
+
+// WithStackDepth is like WithStack, capturing up to depth frames.
+func WithStackDepth(depth int) Option {
+	return func(err *Error) {
+		err.pcs, err.stackDepth = callers(depth)
+	}
+}
+
+// Frames resolves the captured program counters into frames, starting from where the error was created.
+func (e *Error) Frames() []Frame {
+	if e == nil || len(e.pcs) == 0 {
+		return nil
+	}
+
+	ret := make([]Frame, 0, len(e.pcs))
+	frames := runtime.CallersFrames(e.pcs)
+	for {
+		f, more := frames.Next()
+		// 跳过本包内部（New、Option 等）位于栈顶的帧
+		if len(ret) > 0 || !internalFrame(f) {
+			ret = append(ret, Frame{Function: f.Function, File: f.File, Line: f.Line})
+		}
+		if !more || len(ret) == e.stackDepth {
+			break
+		}
+	}
+	return ret
+}
+
+// goError has the fields of Error without its methods, to print it in Go syntax.
+type goError Error
+
+// Format implements fmt.Formatter: %+v prints the error followed by its stack frames, %#v prints it in Go syntax,
+// and other verbs format the text of the error as a string, e.g. %s, %q and %x.
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('#'):
+		if e == nil {
+			io.WriteString(s, "(*errors.Error)(nil)")
+			return
+		}
+		io.WriteString(s, "&errors.Error"+strings.TrimPrefix(fmt.Sprintf("%#v", (*goError)(e)), "&errors.goError"))
+	case verb == 'v':
+		io.WriteString(s, e.Error())
+		if s.Flag('+') && len(e.pcs) > 0 {
+			io.WriteString(s, "\n")
+			io.WriteString(s, renderFrames(e.Frames()))
+		}
+	default:
+		fmt.Fprintf(s, fmt.FormatString(s, verb), e.Error())
+	}
+}
+
+// callers captures the program counters of the caller's stack for up to depth frames,
+// plus a few more to make up for the frames of this package trimmed by Frames.
+func callers(depth int) ([]uintptr, int) {
+	if depth <= 0 {
+		return nil, 0
+	}
+	pcs := make([]uintptr, depth+8)
+	n := runtime.Callers(2, pcs)
+	return pcs[:n:n], depth
+}
+
+// renderFrames renders frames in the same layout as runtime.Stack.
+func renderFrames(frames []Frame) string {
+	var b strings.Builder
+	for i, f := range frames {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(f.String())
+	}
+	return b.String()
+}
@@ -0,0 +1,92 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func newWithStack() ErrorIface {
+	return New(500, "Internal", "", nil, WithStack())
+}
+
+func TestError_Frames(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   *Error
+		first string
+		depth int
+	}{
+		{name: "case1", err: newWithStack().(*Error), first: pkgPrefix + "newWithStack"},
+		{name: "case2", err: New(500, "", "", nil, WithStackDepth(1)).(*Error), first: pkgPrefix + "TestError_Frames", depth: 1},
+		{name: "case3", err: Wrap(fmt.Errorf("eof"), 500, "", "", WithStack()).(*Error), first: pkgPrefix + "TestError_Frames"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames := tt.err.Frames()
+			if len(frames) == 0 || frames[0].Function != tt.first {
+				t.Fatalf("Error.Frames() = %v, want first frame %v", frames, tt.first)
+			}
+			if !strings.HasSuffix(frames[0].File, "stack_test.go") || frames[0].Line == 0 {
+				t.Errorf("Error.Frames()[0] = %v, want a line in stack_test.go", frames[0])
+			}
+			if tt.depth > 0 && len(frames) > tt.depth {
+				t.Errorf("Error.Frames() = %d frames, want at most %d", len(frames), tt.depth)
+			}
+		})
+	}
+
+	if frames := New(500, "", "", nil).(*Error).Frames(); frames != nil {
+		t.Errorf("Error.Frames() without WithStack = %v, want nil", frames)
+	}
+}
+
+func TestError_Format(t *testing.T) {
+	err := newWithStack()
+	for _, verb := range []string{"%s", "%q", "%x", "%10.4s"} {
+		if got, want := fmt.Sprintf(verb, err), fmt.Sprintf(verb, err.Error()); got != want {
+			t.Errorf("Sprintf(%s) = %v, want %v", verb, got, want)
+		}
+	}
+	if got := fmt.Sprintf("%#v", New(404, "NotFound", "", nil)); !strings.HasPrefix(got, `&errors.Error{code:404, reason:"NotFound"`) {
+		t.Errorf("Sprintf(%%#v) = %v, want Go syntax", got)
+	}
+	if got := fmt.Sprintf("%v", err); got != err.Error() {
+		t.Errorf("Sprintf(%%v) = %v, want %v", got, err.Error())
+	}
+	got := fmt.Sprintf("%+v", err)
+	if !strings.HasPrefix(got, err.Error()+"\n"+pkgPrefix+"newWithStack\n\t") {
+		t.Errorf("Sprintf(%%+v) = %v, want the error followed by frames", got)
+	}
+	if stack := err.GetStack(); !strings.HasPrefix(stack, pkgPrefix+"newWithStack\n\t") {
+		t.Errorf("Error.GetStack() = %v", stack)
+	}
+}
+
+func TestWithStackTrace(t *testing.T) {
+	tests := []struct {
+		name  string
+		size  int
+		want  int
+		first string
+	}{
+		{name: "case1", size: 0, want: 0},
+		{name: "case2", size: 10, want: 10},
+		{name: "case3", size: 1 << 16, first: pkgPrefix + "TestWithStackTrace"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New(500, "", "", nil, WithStackTrace(tt.size))
+			stack := err.GetStack()
+			if tt.first != "" && !strings.HasPrefix(stack, tt.first) {
+				t.Fatalf("Error.GetStack() = %q, want the frames of this package trimmed", stack)
+			}
+			if tt.first == "" && len(stack) != tt.want {
+				t.Fatalf("Error.GetStack() = %q, want %d bytes", stack, tt.want)
+			}
+			if !strings.Contains(err.Error(), "stack: "+stack+",") {
+				t.Errorf("Error.Error() = %v, want the stack", err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var _ error = (*ValidationError)(nil) // make sure ValidationError implements error interface
+
+// Code and reason of the ErrorIface converted from a ValidationError.
+const (
+	ValidationCode   = 400
+	ValidationReason = "ValidationFailed"
+)
+
+// Issue is a single invalid field of a request.
+type Issue struct {
+	Field   string `json:"field"` // path of the field, e.g. `items[2][name]`, see FieldPath
+	Rule    string `json:"rule"`  // name of the violated rule, e.g. "required"
+	Value   any    `json:"value"` // rejected value
+	Message string `json:"message"`
+}
+
+// String formats the issue as `field: message (rule)`.
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s (%s)", i.Field, i.Message, i.Rule)
+}
+
+// ValidationError collects the issues of a request, so that every bad field is reported in one response.
+// The zero value is an empty ValidationError; it is NOT safe for concurrent use.
+type ValidationError struct {
+	issues []Issue
+}
+
+// NewValidationError returns a pointer to a new empty ValidationError.
+func NewValidationError() *ValidationError {
+	return &ValidationError{}
+}
+
+// Add appends an issue on field.
+func (v *ValidationError) Add(field, rule string, value any, message string) *ValidationError {
+	v.issues = append(v.issues, Issue{Field: field, Rule: rule, Value: value, Message: message})
+	return v
+}
+
+// Addf appends an issue on field, with a message formatted from format and args.
+func (v *ValidationError) Addf(field, rule string, value any, format string, args ...any) *ValidationError {
+	return v.Add(field, rule, value, fmt.Sprintf(format, args...))
+}
+
+// Issues returns a copy of the issues collected.
+func (v *ValidationError) Issues() []Issue {
+	if v == nil {
+		return nil
+	}
+	ret := make([]Issue, len(v.issues))
+	copy(ret, v.issues)
+	return ret
+}
+
+// Len returns the number of issues collected.
+func (v *ValidationError) Len() int {
+	if v == nil {
+		return 0
+	}
+	return len(v.issues)
+}
+
+// Error joins the issues by "; "; it implements standard `error` interface.
+func (v *ValidationError) Error() string {
+	msgs := make([]string, len(v.issues))
+	for i, issue := range v.issues {
+		msgs[i] = issue.String()
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// ToError converts the ValidationError to an ErrorIface, with code ValidationCode, reason ValidationReason, and the issues as data.
+// The passed-in options are applied after, e.g. to set another code. It returns nil if no issue is collected.
+func (v *ValidationError) ToError(options ...Option) ErrorIface {
+	if v.Len() == 0 {
+		return nil
+	}
+	return NewWithSkip(2, ValidationCode, ValidationReason, v.message(), v.Issues(), options...)
+}
+
+// message is the message of the converted error.
+func (v *ValidationError) message() string {
+	if len(v.issues) == 1 {
+		return v.issues[0].Field + ": " + v.issues[0].Message
+	}
+	return fmt.Sprintf("%d fields are invalid", len(v.issues))
+}
+
+// ValidationIssues returns the issues of the first ValidationError in err's chain,
+// or the data of an error converted from a ValidationError.
+func ValidationIssues(err error) []Issue {
+	if v := new(ValidationError); errors.As(err, &v) {
+		return v.Issues()
+	}
+	if se := new(Error); errors.As(err, &se) {
+		if issues, ok := se.data.([]Issue); ok {
+			return issues
+		}
+	}
+	return nil
+}
+
+// FieldPath joins the parts into a field path in the bracket syntax of utils.ParseFormData,
+// e.g. FieldPath("items", 2, "name") returns `items[2][name]`.
+func FieldPath(root string, parts ...any) string {
+	var sb strings.Builder
+	sb.WriteString(root)
+	for _, part := range parts {
+		sb.WriteByte('[')
+		sb.WriteString(fmt.Sprint(part))
+		sb.WriteByte(']')
+	}
+	return sb.String()
+}
@@ -0,0 +1,82 @@
+package errors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestFieldPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		root  string
+		parts []any
+		want  string
+	}{
+		{name: "case1", root: "name", parts: nil, want: "name"},
+		{name: "case2", root: "items", parts: []any{2, "name"}, want: "items[2][name]"},
+		{name: "case3", root: "tags", parts: []any{"color", 0}, want: "tags[color][0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FieldPath(tt.root, tt.parts...); got != tt.want {
+				t.Errorf("FieldPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidationError_ToError(t *testing.T) {
+	tests := []struct {
+		name        string
+		issues      []Issue
+		wantNil     bool
+		wantMessage string
+	}{
+		{name: "case1", issues: nil, wantNil: true},
+		{name: "case2", issues: []Issue{{Field: "items[2][name]", Rule: "required", Value: "", Message: "is required"}},
+			wantMessage: "items[2][name]: is required"},
+		{name: "case3", issues: []Issue{{Field: "age", Rule: "min", Value: -1, Message: "must be positive"}, {Field: "email", Rule: "email", Value: "x", Message: "is invalid"}},
+			wantMessage: "2 fields are invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewValidationError()
+			for _, issue := range tt.issues {
+				v.Add(issue.Field, issue.Rule, issue.Value, issue.Message)
+			}
+			got := v.ToError()
+			if tt.wantNil {
+				if got != nil {
+					t.Errorf("ToError() = %v, want nil", got)
+				}
+				return
+			}
+			if got.GetCode() != ValidationCode || got.GetReason() != ValidationReason || got.GetMessage() != tt.wantMessage {
+				t.Errorf("ToError() = %v, want code %d and message %q", got, ValidationCode, tt.wantMessage)
+			}
+			if !reflect.DeepEqual(ValidationIssues(got), tt.issues) {
+				t.Errorf("ValidationIssues() = %v, want %v", ValidationIssues(got), tt.issues)
+			}
+		})
+	}
+}
+
+func TestWriteHTTP_ValidationError(t *testing.T) {
+	v := new(ValidationError).
+		Add("items[0][name]", "required", "", "is required").
+		Addf("items[1][qty]", "max", 200, "must be at most %d", 100)
+
+	rec := httptest.NewRecorder()
+	WriteHTTP(rec, httptest.NewRequest(http.MethodPost, "/items", nil), v)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("WriteHTTP() status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	want := `{"code":400,"reason":"ValidationFailed","message":"2 fields are invalid","data":[` +
+		`{"field":"items[0][name]","rule":"required","value":"","message":"is required"},` +
+		`{"field":"items[1][qty]","rule":"max","value":200,"message":"must be at most 100"}]}` + "\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("WriteHTTP() body = %s, want %s", got, want)
+	}
+}
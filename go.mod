@@ -1,12 +1,14 @@
 module awesome-pkg
 
-go 1.18
+go 1.21
 
-require go.uber.org/zap v1.19.1
+require (
+	go.uber.org/multierr v1.7.0
+	go.uber.org/zap v1.19.1
+	gopkg.in/yaml.v2 v2.4.0
+)
 
 require (
 	github.com/pkg/errors v0.9.1 // indirect
 	go.uber.org/atomic v1.9.0 // indirect
-	go.uber.org/multierr v1.7.0 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
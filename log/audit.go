@@ -0,0 +1,278 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AuditGenesis is the prev hash of the very first entry of an audit chain.
+const AuditGenesis = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// auditHashSuffixLen is the length of what follows the entry body on each line: `,"hash":"<64 hex>"}`
+const auditHashSuffixLen = len(`,"hash":""}`) + sha256.Size*2
+
+// ErrAuditEncrypted is returned by Register when AuditLogFile is set along with WithEncryption in RotateLogOptions:
+// the audit chain is resumed and verified on plaintext segments.
+var ErrAuditEncrypted = errors.New("audit log does not support encrypted segments")
+
+// AuditEntry is one line of an audit log.
+// Hash is sha256(Prev + body), where body is the JSON encoding of the entry without the hash field,
+// and it is always the last member of the line, hence the chain can be verified on the raw bytes.
+type AuditEntry struct {
+	Seq    uint64         `json:"seq"`
+	Time   time.Time      `json:"ts"`
+	Event  string         `json:"event"`
+	Fields map[string]any `json:"fields,omitempty"`
+	Prev   string         `json:"prev"`
+	Hash   string         `json:"hash,omitempty"`
+}
+
+// Audit is a tamper-evident logger: every entry carries a sequence number and a hash chained to the previous entry.
+// Used on top of a RotateLog, the chain continues across segments.
+// An Audit must be the only writer of its chain; do not share the destination between processes.
+type Audit struct {
+	mu   sync.Mutex
+	w    io.Writer
+	seq  uint64
+	prev string
+}
+
+// NewAudit returns an Audit starting a new chain on w.
+func NewAudit(w io.Writer) *Audit {
+	return &Audit{w: w, prev: AuditGenesis}
+}
+
+// ResumeAudit returns an Audit continuing the chain whose last entry is the last line of the file at path.
+// A missing or empty file starts a new chain; see ResumeAuditGlob to resume from rotated segments.
+func ResumeAudit(w io.Writer, path string) (*Audit, error) {
+	a := NewAudit(w)
+	entry, err := lastAuditEntry(path)
+	if err != nil || entry == nil {
+		return a, err
+	}
+	a.seq, a.prev = entry.Seq, entry.Hash
+	return a, nil
+}
+
+// ResumeAuditGlob returns an Audit continuing the chain from the last entry of the newest non-empty segment matching pattern,
+// e.g. logpath + AuditLogFile + "_*.log", so that the chain continues when the current segment is still empty after a restart.
+// Segments are ordered as by VerifyAuditGlob. No matching entry starts a new chain.
+func ResumeAuditGlob(w io.Writer, pattern string) (*Audit, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sortSegments(paths)
+
+	a := NewAudit(w)
+	for i := len(paths) - 1; i >= 0; i-- {
+		entry, err := lastAuditEntry(paths[i])
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			a.seq, a.prev = entry.Seq, entry.Hash
+			break
+		}
+	}
+	return a, nil
+}
+
+// lastAuditEntry returns the last entry of the file at path, nil if the file is missing or empty.
+func lastAuditEntry(path string) (*AuditEntry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var last []byte
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			last = append(last[:0], line...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if last == nil {
+		return nil, nil
+	}
+
+	var entry AuditEntry
+	if err := json.Unmarshal(last, &entry); err != nil {
+		return nil, fmt.Errorf("resume audit from %s: %w", path, err)
+	}
+	return &entry, nil
+}
+
+// Log appends a new entry to the chain. If the write fails, the error is returned and the chain does not advance.
+func (a *Audit) Log(event string, fields map[string]any) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entry := AuditEntry{
+		Seq:    a.seq + 1,
+		Time:   time.Now(),
+		Event:  event,
+		Fields: fields,
+		Prev:   a.prev,
+	}
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	hash := auditHash(entry.Prev, body)
+
+	line := make([]byte, 0, len(body)+auditHashSuffixLen+1)
+	line = append(line, body[:len(body)-1]...)
+	line = append(line, `,"hash":"`...)
+	line = append(line, hash...)
+	line = append(line, "\"}\n"...)
+	if _, err := a.w.Write(line); err != nil {
+		return err
+	}
+
+	a.seq, a.prev = entry.Seq, hash
+	return nil
+}
+
+// Sync flushes the underlying writer if it supports it.
+func (a *Audit) Sync() error {
+	if s, ok := a.w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// AuditBreak describes the first broken or missing link found by VerifyAudit.
+type AuditBreak struct {
+	Path   string // segment where the break is found
+	Line   int    // line number in the segment, starting from 1
+	Seq    uint64 // sequence number expected at this position
+	Reason string
+}
+
+// Error implements the standard `error` interface.
+func (b *AuditBreak) Error() string {
+	return fmt.Sprintf("audit chain broken at %s:%d (expected seq %d): %s", b.Path, b.Line, b.Seq, b.Reason)
+}
+
+// VerifyAudit scans the given segments in order and checks the sequence numbers and hash chain.
+// It returns the number of entries verified, and an *AuditBreak describing the first broken or missing link, if any.
+// Other errors are I/O errors.
+func VerifyAudit(paths ...string) (verified uint64, err error) {
+	seq, prev := uint64(0), AuditGenesis
+	for _, path := range paths {
+		if seq, prev, err = verifyAuditSegment(path, seq, prev); err != nil {
+			return seq, err
+		}
+	}
+	return seq, nil
+}
+
+// VerifyAuditGlob verifies all segments matching pattern, e.g. logpath + AuditLogFile + "_*.log".
+// Segments are ordered by the numbers in their names, so that `_2024_1_10` comes after `_2024_1_9`.
+// Make sure the pattern does not match the link file, which duplicates the current segment.
+func VerifyAuditGlob(pattern string) (verified uint64, err error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return 0, err
+	}
+	sortSegments(paths)
+	return VerifyAudit(paths...)
+}
+
+// verifyAuditSegment verifies one segment, continuing the chain from seq and prev.
+func verifyAuditSegment(path string, seq uint64, prev string) (uint64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return seq, prev, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		brk := &AuditBreak{Path: path, Line: lineNo, Seq: seq + 1}
+
+		var entry AuditEntry
+		if err := json.Unmarshal(line, &entry); err != nil || len(line) < auditHashSuffixLen+1 {
+			brk.Reason = "malformed entry"
+			return seq, prev, brk
+		}
+		switch {
+		case entry.Seq != seq+1:
+			brk.Reason = fmt.Sprintf("got seq %d, entries are missing or reordered", entry.Seq)
+			return seq, prev, brk
+		case entry.Prev != prev:
+			brk.Reason = "prev hash does not match the previous entry"
+			return seq, prev, brk
+		}
+
+		body := append(line[:len(line)-auditHashSuffixLen:len(line)-auditHashSuffixLen], '}')
+		if auditHash(entry.Prev, body) != entry.Hash {
+			brk.Reason = "hash does not match the entry content"
+			return seq, prev, brk
+		}
+		seq, prev = entry.Seq, entry.Hash
+	}
+
+	return seq, prev, scanner.Err()
+}
+
+// auditHash returns the hex sha256 of prev followed by body.
+func auditHash(prev string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(prev))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+var reDigits = regexp.MustCompile(`[0-9]+`)
+
+// sortSegments sorts segment paths by the numbers in their names.
+func sortSegments(paths []string) {
+	key := func(p string) []int {
+		var ret []int
+		for _, d := range reDigits.FindAllString(filepath.Base(p), -1) {
+			n, _ := strconv.Atoi(d)
+			ret = append(ret, n)
+		}
+		return ret
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		ki, kj := key(paths[i]), key(paths[j])
+		for n := 0; n < len(ki) && n < len(kj); n++ {
+			if ki[n] != kj[n] {
+				return ki[n] < kj[n]
+			}
+		}
+		if len(ki) != len(kj) {
+			return len(ki) < len(kj)
+		}
+		return paths[i] < paths[j]
+	})
+}
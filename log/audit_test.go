@@ -0,0 +1,101 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResumeAuditGlob(t *testing.T) {
+	dir := t.TempDir()
+	pattern := filepath.Join(dir, "audit_*.log")
+
+	// 第一天写入两条记录，第二天重启时当前分段仍为空
+	day1, err := os.Create(filepath.Join(dir, "audit_2024_1_9.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewAudit(day1)
+	for _, event := range []string{"login", "grant"} {
+		if err := a.Log(event, map[string]any{"user": "tom"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	day1.Close()
+
+	day2, err := os.Create(filepath.Join(dir, "audit_2024_1_10.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer day2.Close()
+	a, err = ResumeAuditGlob(day2, pattern)
+	if err != nil {
+		t.Fatalf("ResumeAuditGlob() error = %v", err)
+	}
+	if err := a.Log("logout", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if verified, err := VerifyAuditGlob(pattern); err != nil || verified != 3 {
+		t.Fatalf("VerifyAuditGlob() = %d, %v, want 3 entries", verified, err)
+	}
+
+	// 篡改第一天的第二条记录
+	path := filepath.Join(dir, "audit_2024_1_9.log")
+	b, _ := os.ReadFile(path)
+	if err := os.WriteFile(path, bytes.Replace(b, []byte(`"grant"`), []byte(`"admin"`), 1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var brk *AuditBreak
+	if _, err := VerifyAuditGlob(pattern); !errors.As(err, &brk) || brk.Line != 2 || brk.Seq != 2 {
+		t.Errorf("VerifyAuditGlob() = %v, want a break at line 2", err)
+	}
+}
+
+func TestRegister_AuditEncrypted(t *testing.T) {
+	defer func(file string, opts []Option) { AuditLogFile, RotateLogOptions = file, opts }(AuditLogFile, RotateLogOptions)
+	AuditLogFile = "audit"
+	RotateLogOptions = []Option{WithEncryption(func() (string, []byte, error) { return "k1", make([]byte, 32), nil })}
+
+	syncers, err := Register(t.TempDir()+"/", "info")
+	for _, s := range syncers {
+		s()
+	}
+	if !errors.Is(err, ErrAuditEncrypted) {
+		t.Errorf("Register() error = %v, want %v", err, ErrAuditEncrypted)
+	}
+}
+
+func TestRegister_AuditNoFallback(t *testing.T) {
+	defer func(file string, opts []Option, rls map[string]*RotateLog) {
+		AuditLogFile, RotateLogOptions, rotateLogs = file, opts, rls
+	}(AuditLogFile, RotateLogOptions, rotateLogs)
+	AuditLogFile = "audit"
+	RotateLogOptions = []Option{WithFailurePolicy(FailFallbackStderr)}
+	rotateLogs = map[string]*RotateLog{}
+
+	syncers, err := Register(t.TempDir()+"/", "info")
+	defer func() {
+		for _, s := range syncers {
+			s()
+		}
+	}()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotateLogs["Error"].policy&FailFallbackStderr == 0 {
+		t.Fatal("RotateLogOptions not applied")
+	}
+
+	// 模拟磁盘故障，审计日志必须收到写入错误
+	arl := rotateLogs["Audit"]
+	arl.file.Close()
+	if err := AuditLogger.Log("login", nil); err == nil {
+		t.Errorf("Audit.Log() error = nil, want the write failure")
+	}
+	if AuditLogger.seq != 0 {
+		t.Errorf("Audit seq = %d after a failed write, want 0", AuditLogger.seq)
+	}
+}
@@ -0,0 +1,212 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	stdlog "log"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Named returns the registered logger of scenario, i.e. one of Error, Request, Call, Debug and Main, or nil.
+func Named(scenario string) *zap.Logger {
+	switch scenario {
+	case "Error":
+		return ErrorLogger
+	case "Request":
+		return RequestLogger
+	case "Call":
+		return CallLogger
+	case "Debug":
+		return DebugLogger
+	case "Main":
+		return Logger
+	}
+	return nil
+}
+
+// RedirectStdLog redirects the output of the standard library's package-global logger
+// to the registered logger of scenario at level, so that third-party output ends up in our files.
+// It returns a function restoring the original prefix, flags and output.
+func RedirectStdLog(scenario string, level zapcore.Level) (restore func(), err error) {
+	l, err := namedLogger(scenario)
+	if err != nil {
+		return nil, err
+	}
+	return zap.RedirectStdLogAt(l, level)
+}
+
+// NewStdLog returns a standard library *log.Logger writing to the registered logger of scenario at level,
+// e.g. for http.Server.ErrorLog.
+func NewStdLog(scenario string, level zapcore.Level) (*stdlog.Logger, error) {
+	l, err := namedLogger(scenario)
+	if err != nil {
+		return nil, err
+	}
+	return zap.NewStdLogAt(l, level)
+}
+
+// NewSlog returns an *slog.Logger writing to the registered logger of scenario.
+func NewSlog(scenario string) (*slog.Logger, error) {
+	l, err := namedLogger(scenario)
+	if err != nil {
+		return nil, err
+	}
+	return slog.New(NewSlogHandler(l)), nil
+}
+
+func namedLogger(scenario string) (*zap.Logger, error) {
+	l := Named(scenario)
+	if l == nil {
+		return nil, fmt.Errorf("logger %q is not registered", scenario)
+	}
+	return l, nil
+}
+
+// SlogHandler is an slog.Handler backed by the core of a zap logger,
+// so that code using slog ends up in the same files with the same encoder.
+// The context of a record is honoured the same way Ctx does: trace fields are added, and WithDebug escalates it.
+type SlogHandler struct {
+	core zapcore.Core
+}
+
+var _ slog.Handler = (*SlogHandler)(nil) // make sure SlogHandler implements slog.Handler
+
+// NewSlogHandler returns a SlogHandler writing to the core of l.
+func NewSlogHandler(l *zap.Logger) *SlogHandler {
+	return &SlogHandler{core: l.Core()}
+}
+
+// Enabled ...
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.coreFor(ctx).Enabled(slogToZapLevel(level))
+}
+
+// Handle ...
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	ent := zapcore.Entry{
+		Level:   slogToZapLevel(r.Level),
+		Time:    r.Time,
+		Message: r.Message,
+	}
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ent.Caller = zapcore.EntryCaller{Defined: true, PC: frame.PC, File: frame.File, Line: frame.Line, Function: frame.Function}
+	}
+
+	ce := h.coreFor(ctx).Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+	fields := make([]zapcore.Field, 0, r.NumAttrs()+3)
+	if ctx != nil {
+		if tc, ok := TraceFrom(ctx); ok {
+			fields = append(fields, tc.Fields()...)
+		}
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, a)
+		return true
+	})
+	ce.Write(fields...)
+	return nil
+}
+
+// WithAttrs ...
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []zapcore.Field
+	for _, a := range attrs {
+		fields = appendAttr(fields, a)
+	}
+	return &SlogHandler{core: h.core.With(fields)}
+}
+
+// WithGroup ...
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{core: h.core.With([]zapcore.Field{zap.Namespace(name)})}
+}
+
+// coreFor returns the core escalated to Debug level if ctx is flagged by WithDebug.
+func (h *SlogHandler) coreFor(ctx context.Context) zapcore.Core {
+	if ctx != nil && IsDebug(ctx) {
+		if lc, ok := h.core.(*leveledCore); ok && !lc.level.Enabled(zapcore.DebugLevel) {
+			return &debugCore{lc}
+		}
+	}
+	return h.core
+}
+
+// slogToZapLevel maps slog levels onto zap levels; anything above Error stays Error.
+func slogToZapLevel(l slog.Level) zapcore.Level {
+	switch {
+	case l < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case l < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case l < slog.LevelError:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+// appendAttr converts a into zap fields following slog's rules: empty attrs are ignored
+// and groups without a key are inlined.
+func appendAttr(fields []zapcore.Field, a slog.Attr) []zapcore.Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if a.Key == "" {
+			for _, ga := range attrs {
+				fields = appendAttr(fields, ga)
+			}
+			return fields
+		}
+		return append(fields, zap.Object(a.Key, slogGroup(attrs)))
+	case slog.KindString:
+		return append(fields, zap.String(a.Key, a.Value.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(a.Key, a.Value.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(a.Key, a.Value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(a.Key, a.Value.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(a.Key, a.Value.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(a.Key, a.Value.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(a.Key, a.Value.Time()))
+	default:
+		return append(fields, zap.Any(a.Key, a.Value.Any()))
+	}
+}
+
+// slogGroup encodes a slog group as a nested object.
+type slogGroup []slog.Attr
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	var fields []zapcore.Field
+	for _, a := range g {
+		fields = appendAttr(fields, a)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l, _, err := newLogger("Main", "info", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sl := slog.New(NewSlogHandler(l)).With("service", "api").WithGroup("req")
+
+	tc := NewTrace()
+	sl.InfoContext(WithTrace(context.Background(), tc), "handled", "status", 200, slog.Group("user", "id", 7))
+	sl.Debug("hidden", "id", "debug")
+	sl.DebugContext(WithDebug(context.Background()), "shown", "id", "escalated")
+
+	out := buf.String()
+	for _, want := range []string{
+		`"level":"INFO"`,
+		`"service":"api"`,
+		`"req":{`,
+		`"status":200`,
+		`"user":{"id":7}`,
+		`"trace_id":"` + tc.TraceID + `"`,
+		`"id":"escalated"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output = %s, want %s", out, want)
+		}
+	}
+	if strings.Contains(out, `"id":"debug"`) {
+		t.Errorf("output = %s, want no Debug entry at info level", out)
+	}
+}
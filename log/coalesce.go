@@ -0,0 +1,162 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewCoalescingCore wraps core so that identical entries (same level, logger name, message and fields, including those added by With)
+// written within window are collapsed: the first one is written immediately, and the repeats are summarized
+// into one more line carrying `repeat`, `first_ts` and `last_ts` when the window closes or the core is synced.
+// `repeat` counts the entries collapsed into the summary, i.e. not the first one already written: 3 identical entries give repeat=2.
+// Loggers derived through With share the pending summaries of the root core, hence a single Sync flushes them all.
+// DPanic and above, and entries carrying NoCoalesce, are never coalesced.
+func NewCoalescingCore(core zapcore.Core, window time.Duration) zapcore.Core {
+	return &coalesceCore{
+		Core:   core,
+		window: window,
+		state:  &coalesceState{pending: map[string]*pendingEntry{}},
+	}
+}
+
+// noCoalesceKey is the key of the field returned by NoCoalesce.
+const noCoalesceKey = "_nocoalesce"
+
+// NoCoalesce returns a field writing the entry it is attached to as is, even through a coalescing core, see NewCoalescingCore.
+// Attached through With, it applies to every entry of the derived logger. The field itself is never encoded.
+func NoCoalesce() zap.Field {
+	return zap.Field{Key: noCoalesceKey, Type: zapcore.SkipType}
+}
+
+// hasNoCoalesce reports whether fields carry NoCoalesce.
+func hasNoCoalesce(fields []zapcore.Field) bool {
+	for _, f := range fields {
+		if f.Key == noCoalesceKey && f.Type == zapcore.SkipType {
+			return true
+		}
+	}
+	return false
+}
+
+// coalesceCore ...
+type coalesceCore struct {
+	zapcore.Core
+	window time.Duration
+	ctxKey string // encoded fields added by With, part of the key of pending entries
+	bypass bool   // NoCoalesce added by With
+
+	state *coalesceState // shared with the cores derived through With
+}
+
+// coalesceState holds the pending summaries of a root core and all its derived cores.
+type coalesceState struct {
+	mutex   sync.Mutex
+	pending map[string]*pendingEntry // repeated entries waiting for their window to close, keyed by level, logger name, message and fields
+}
+
+// pendingEntry is an entry already written once, with the repeats seen since.
+type pendingEntry struct {
+	core    zapcore.Core // core of the logger the entry is written with
+	ent     zapcore.Entry
+	fields  []zapcore.Field
+	repeats int // identical entries seen after the first one, written as `repeat`
+	last    time.Time
+}
+
+// With ...
+func (c *coalesceCore) With(fields []zapcore.Field) zapcore.Core {
+	return &coalesceCore{
+		Core:   c.Core.With(fields),
+		window: c.window,
+		ctxKey: c.ctxKey + encodeFields(fields),
+		bypass: c.bypass || hasNoCoalesce(fields),
+		state:  c.state,
+	}
+}
+
+// Check ...
+func (c *coalesceCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write ...
+func (c *coalesceCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Level >= zapcore.DPanicLevel || c.bypass || hasNoCoalesce(fields) {
+		return c.Core.Write(ent, fields)
+	}
+
+	key := fmt.Sprintf("%d|%s|%s|%s|%s", ent.Level, ent.LoggerName, ent.Message, c.ctxKey, encodeFields(fields))
+
+	c.state.mutex.Lock()
+	if p, ok := c.state.pending[key]; ok {
+		p.repeats++
+		p.last = ent.Time
+		c.state.mutex.Unlock()
+		return nil
+	}
+	p := &pendingEntry{core: c.Core, ent: ent, fields: fields, last: ent.Time}
+	c.state.pending[key] = p
+	c.state.mutex.Unlock()
+
+	time.AfterFunc(c.window, func() {
+		// Sync 可能已经写出了 p，key 也可能已经属于之后的新窗口
+		c.state.mutex.Lock()
+		current := c.state.pending[key] == p
+		if current {
+			delete(c.state.pending, key)
+		}
+		c.state.mutex.Unlock()
+
+		if current {
+			_ = p.flush()
+		}
+	})
+	// 首次出现立即写入，窗口内的重复只在窗口结束时汇总一行
+	return c.Core.Write(ent, fields)
+}
+
+// Sync writes the summaries of every pending entry, including those of derived cores, before syncing the wrapped core.
+func (c *coalesceCore) Sync() error {
+	c.state.mutex.Lock()
+	pending := c.state.pending
+	c.state.pending = map[string]*pendingEntry{}
+	c.state.mutex.Unlock()
+
+	for _, p := range pending {
+		_ = p.flush()
+	}
+	return c.Core.Sync()
+}
+
+// flush writes the summary of the repeats of p, if any, with the repeat count and the timestamps of the first and last repeat.
+func (p *pendingEntry) flush() error {
+	if p.repeats == 0 {
+		return nil
+	}
+	fields := append(p.fields[:len(p.fields):len(p.fields)],
+		zap.Int("repeat", p.repeats),
+		zap.Time("first_ts", p.ent.Time),
+		zap.Time("last_ts", p.last),
+	)
+	return p.core.Write(p.ent, fields)
+}
+
+// encodeFields encodes fields into a stable string, to identify identical entries.
+func encodeFields(fields []zapcore.Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	// fmt 打印 map 时按 key 排序，结果稳定
+	return fmt.Sprintf("%v", enc.Fields)
+}
@@ -0,0 +1,65 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCoalescingCore(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	l := zap.New(NewCoalescingCore(obs, time.Hour))
+	derived := l.With(zap.String("trace_id", "t1"))
+
+	for i := 0; i < 3; i++ {
+		l.Error("db down", zap.Int("port", 5432))
+		derived.Error("db down", zap.Int("port", 5432))
+	}
+	l.Error("db down", zap.Int("port", 3306))
+
+	// 首次出现立即写入
+	if got := logs.Len(); got != 3 {
+		t.Fatalf("before Sync: %d entries, want 3", got)
+	}
+
+	// 由 With 派生的 logger 共享待汇总的条目，Sync 一次全部写出
+	if err := l.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	summaries := logs.FilterField(zap.Int("repeat", 2)).AllUntimed()
+	if len(summaries) != 2 {
+		t.Fatalf("after Sync: %d summaries with repeat=2, want 2, entries: %v", len(summaries), logs.AllUntimed())
+	}
+	if got := logs.FilterField(zap.String("trace_id", "t1")).Len(); got != 2 {
+		t.Errorf("entries of the derived logger = %d, want 2", got)
+	}
+	if got := logs.Len(); got != 5 {
+		t.Errorf("after Sync: %d entries, want 5", got)
+	}
+}
+
+func TestCoalescingCore_WindowAfterSync(t *testing.T) {
+	const window = 200 * time.Millisecond
+	obs, logs := observer.New(zapcore.DebugLevel)
+	l := zap.New(NewCoalescingCore(obs, window))
+
+	l.Error("db down")
+	l.Sync()
+	time.Sleep(window / 2)
+
+	// Sync 之后开始新的窗口，之前的定时器不能提前结束它
+	l.Error("db down")
+	l.Error("db down")
+	time.Sleep(window * 3 / 4)
+	if got := logs.Len(); got != 2 {
+		t.Fatalf("%d entries before the new window closes, want 2: %v", got, logs.AllUntimed())
+	}
+
+	time.Sleep(window)
+	if got := logs.FilterField(zap.Int("repeat", 1)).Len(); got != 1 || logs.Len() != 3 {
+		t.Errorf("entries after the new window closes: %v, want one summary with repeat=1", logs.AllUntimed())
+	}
+}
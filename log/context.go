@@ -0,0 +1,127 @@
+package log
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DebugHeader is the request header carrying a signed debug token, see SignDebugToken.
+const DebugHeader = "X-Debug-Token"
+
+type debugCtxKey struct{}
+
+// WithDebug returns a copy of ctx in which log calls made through Ctx emit Debug-level entries.
+func WithDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugCtxKey{}, true)
+}
+
+// IsDebug reports whether ctx has been flagged by WithDebug.
+func IsDebug(ctx context.Context) bool {
+	debug, _ := ctx.Value(debugCtxKey{}).(bool)
+	return debug
+}
+
+// Ctx returns the logger to use for the request carried by ctx.
+// If ctx carries a TraceContext, every entry carries its trace_id and span_id.
+// If ctx is flagged by WithDebug, the returned logger emits Debug-level entries to the same destination as l,
+// while the AtomicLevel of l, hence the level of every other request, stays untouched.
+func Ctx(ctx context.Context, l *zap.Logger) *zap.Logger {
+	if l == nil || ctx == nil {
+		return l
+	}
+	if tc, ok := TraceFrom(ctx); ok {
+		l = l.With(tc.Fields()...)
+	}
+	return escalate(ctx, l)
+}
+
+// escalate returns l emitting Debug-level entries if ctx is flagged by WithDebug, l otherwise.
+func escalate(ctx context.Context, l *zap.Logger) *zap.Logger {
+	if !IsDebug(ctx) {
+		return l
+	}
+	return l.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		if lc, ok := c.(*leveledCore); ok && !lc.level.Enabled(zapcore.DebugLevel) {
+			return &debugCore{lc}
+		}
+		return c
+	}))
+}
+
+// DebugMiddleware flags the request context with WithDebug when it carries a valid DebugHeader signed with secret.
+func DebugMiddleware(secret []byte, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.Header.Get(DebugHeader); token != "" && VerifyDebugToken(secret, token, time.Now()) {
+			r = r.WithContext(WithDebug(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SignDebugToken returns a debug token valid until expiry, in the form of `<unix expiry>.<hex hmac-sha256>`.
+func SignDebugToken(secret []byte, expiry time.Time) string {
+	exp := strconv.FormatInt(expiry.Unix(), 10)
+	return exp + "." + debugTokenMAC(secret, exp)
+}
+
+// VerifyDebugToken reports whether token is signed with secret and not expired at now.
+func VerifyDebugToken(secret []byte, token string, now time.Time) bool {
+	exp, mac, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	expiry, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || now.Unix() > expiry {
+		return false
+	}
+	return hmac.Equal([]byte(mac), []byte(debugTokenMAC(secret, exp)))
+}
+
+func debugTokenMAC(secret []byte, exp string) string {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(exp))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// leveledCore keeps the AtomicLevel created by newLogger next to the core it gates.
+type leveledCore struct {
+	zapcore.Core
+	level zap.AtomicLevel
+}
+
+// With adds structured context to the Core, keeping the AtomicLevel.
+func (c *leveledCore) With(fields []zapcore.Field) zapcore.Core {
+	return &leveledCore{Core: c.Core.With(fields), level: c.level}
+}
+
+// debugCore lets Debug-level entries through regardless of the AtomicLevel of the wrapped core.
+type debugCore struct {
+	*leveledCore
+}
+
+// Enabled ...
+func (c *debugCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= zapcore.DebugLevel
+}
+
+// With ...
+func (c *debugCore) With(fields []zapcore.Field) zapcore.Core {
+	return &debugCore{&leveledCore{Core: c.Core.With(fields), level: c.level}}
+}
+
+// Check adds the debugCore itself, since the wrapped core would filter the entry by its own level.
+func (c *debugCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
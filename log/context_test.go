@@ -0,0 +1,60 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestCtx_WithDebug(t *testing.T) {
+	var buf bytes.Buffer
+	l, _, err := newLogger("Request", "info", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Debug("hidden", zap.String("id", "global"))
+	Ctx(WithDebug(context.Background()), l).Debug("shown", zap.String("id", "escalated"))
+	Ctx(context.Background(), l).Debug("hidden", zap.String("id", "plain"))
+
+	out := buf.String()
+	if !strings.Contains(out, `"id":"escalated"`) || !strings.Contains(out, `"level":"DEBUG"`) {
+		t.Errorf("output = %s, want the Debug entry of the escalated logger", out)
+	}
+	if strings.Contains(out, `"id":"global"`) || strings.Contains(out, `"id":"plain"`) {
+		t.Errorf("output = %s, want the level of the other loggers untouched", out)
+	}
+	if l.Core().Enabled(zap.DebugLevel) {
+		t.Errorf("the global level is escalated")
+	}
+}
+
+func TestVerifyDebugToken(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Now()
+	token := SignDebugToken(secret, now.Add(time.Minute))
+
+	tests := []struct {
+		name   string
+		secret []byte
+		token  string
+		now    time.Time
+		want   bool
+	}{
+		{name: "case1", secret: secret, token: token, now: now, want: true},
+		{name: "case2", secret: []byte("other"), token: token, now: now, want: false},
+		{name: "case3", secret: secret, token: token, now: now.Add(time.Hour), want: false},
+		{name: "case4", secret: secret, token: "garbage", now: now, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyDebugToken(tt.secret, tt.token, tt.now); got != tt.want {
+				t.Errorf("VerifyDebugToken() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package log
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Encrypted segments are a sequence of frames, so that appends stay streamable:
+//
+//	header: 'H' | "RLGCM1" | uint8 len(keyID) | keyID | int64 unix nano
+//	data:   'D' | uint8 len(keyID) | keyID | 12-byte nonce | uint32 len(ciphertext) | ciphertext
+//
+// A header frame is written every time a segment is opened, hence records each key rotation.
+// Each data frame is one Write, or a chunk of maxFramePlaintext bytes of a larger one, sealed with AES-GCM using its keyID as additional data.
+// A Write failing in the middle of its frames is truncated from the segment, so that the next frame follows a complete one.
+const (
+	frameHeader = 'H'
+	frameData   = 'D'
+
+	frameMagic = "RLGCM1"
+
+	maxFramePlaintext = 1 << 20 // plaintext size of a data frame, also bounding what DecryptReader allocates
+)
+
+// KeyFunc returns the key used to encrypt the next segment, and its ID recorded in the segment.
+// The key must be 16, 24 or 32 bytes long, selecting AES-128, AES-192 or AES-256.
+type KeyFunc func() (keyID string, key []byte, err error)
+
+// KeyLookup returns the key of keyID for decryption.
+type KeyLookup func(keyID string) (key []byte, err error)
+
+// SegmentHeader is the key rotation record written at the head of each opened segment.
+type SegmentHeader struct {
+	KeyID string
+	Time  time.Time
+}
+
+// WithEncryption encrypts segments with AES-GCM in framed chunks, using the key returned by keys at each rotation.
+// Read them back with NewDecryptReader. FailFallbackStderr does not apply, so that plaintext never leaks to stderr.
+func WithEncryption(keys KeyFunc) Option {
+	return func(r *RotateLog) {
+		r.keyFunc = keys
+	}
+}
+
+// newSegmentCipher fetches a key through keys and returns its AEAD along with the header frame to write.
+func newSegmentCipher(keys KeyFunc, now time.Time) (aead cipher.AEAD, keyID string, header []byte, err error) {
+	keyID, key, err := keys()
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if len(keyID) > 255 {
+		return nil, "", nil, fmt.Errorf("key ID too long: %d bytes", len(keyID))
+	}
+	if aead, err = newAEAD(key); err != nil {
+		return nil, "", nil, err
+	}
+
+	header = append([]byte{frameHeader}, frameMagic...)
+	header = append(header, byte(len(keyID)))
+	header = append(header, keyID...)
+	header = binary.BigEndian.AppendUint64(header, uint64(now.UnixNano()))
+	return aead, keyID, header, nil
+}
+
+// sealFrames encrypts b into data frames of at most maxFramePlaintext bytes of plaintext each.
+func sealFrames(aead cipher.AEAD, keyID string, b []byte) ([]byte, error) {
+	var frames []byte
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > maxFramePlaintext {
+			chunk = chunk[:maxFramePlaintext]
+		}
+		frame, err := sealFrame(aead, keyID, chunk)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame...)
+		b = b[len(chunk):]
+	}
+	return frames, nil
+}
+
+// sealFrame encrypts b into a data frame.
+func sealFrame(aead cipher.AEAD, keyID string, b []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	frame := make([]byte, 0, 2+len(keyID)+len(nonce)+4+len(b)+aead.Overhead())
+	frame = append(frame, frameData, byte(len(keyID)))
+	frame = append(frame, keyID...)
+	frame = append(frame, nonce...)
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(b)+aead.Overhead()))
+	return aead.Seal(frame, nonce, b, []byte(keyID)), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// DecryptReader reads the plaintext of a segment encrypted by WithEncryption.
+type DecryptReader struct {
+	r       *bufio.Reader
+	lookup  KeyLookup
+	aeads   map[string]cipher.AEAD
+	headers []SegmentHeader
+	buf     []byte // plaintext not read yet
+}
+
+// NewDecryptReader returns a DecryptReader reading the encrypted segment from r, fetching keys through lookup.
+// A segment truncated in the middle of a frame, e.g. by a crash, ends with io.ErrUnexpectedEOF,
+// and a corrupted frame length is reported as an error instead of being allocated.
+func NewDecryptReader(r io.Reader, lookup KeyLookup) *DecryptReader {
+	return &DecryptReader{r: bufio.NewReader(r), lookup: lookup, aeads: map[string]cipher.AEAD{}}
+}
+
+// Headers returns the segment headers, i.e. key rotations, read so far.
+func (d *DecryptReader) Headers() []SegmentHeader {
+	return d.headers
+}
+
+// Read implements io.Reader.
+func (d *DecryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+// next reads the next frame, decrypting it into d.buf if it is a data frame.
+func (d *DecryptReader) next() error {
+	typ, err := d.r.ReadByte()
+	if err != nil {
+		return err // io.EOF at a frame boundary is a clean end
+	}
+
+	switch typ {
+	case frameHeader:
+		magic := make([]byte, len(frameMagic))
+		if err := d.readFull(magic); err != nil {
+			return err
+		}
+		if string(magic) != frameMagic {
+			return errors.New("unsupported encrypted segment version")
+		}
+		keyID, err := d.readKeyID()
+		if err != nil {
+			return err
+		}
+		var ts [8]byte
+		if err := d.readFull(ts[:]); err != nil {
+			return err
+		}
+		d.headers = append(d.headers, SegmentHeader{KeyID: keyID, Time: time.Unix(0, int64(binary.BigEndian.Uint64(ts[:])))})
+		return nil
+
+	case frameData:
+		keyID, err := d.readKeyID()
+		if err != nil {
+			return err
+		}
+		aead, err := d.aead(keyID)
+		if err != nil {
+			return err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if err := d.readFull(nonce); err != nil {
+			return err
+		}
+		var size [4]byte
+		if err := d.readFull(size[:]); err != nil {
+			return err
+		}
+		n := binary.BigEndian.Uint32(size[:])
+		if n > maxFramePlaintext+uint32(aead.Overhead()) {
+			return fmt.Errorf("data frame of %d bytes exceeds the maximum, the segment is corrupted", n)
+		}
+		ciphertext := make([]byte, n)
+		if err := d.readFull(ciphertext); err != nil {
+			return err
+		}
+		if d.buf, err = aead.Open(ciphertext[:0], nonce, ciphertext, []byte(keyID)); err != nil {
+			return fmt.Errorf("decrypt frame with key %q: %w", keyID, err)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown frame type %#x", typ)
+	}
+}
+
+func (d *DecryptReader) readKeyID() (string, error) {
+	n, err := d.r.ReadByte()
+	if err != nil {
+		return "", io.ErrUnexpectedEOF
+	}
+	keyID := make([]byte, n)
+	if err := d.readFull(keyID); err != nil {
+		return "", err
+	}
+	return string(keyID), nil
+}
+
+// readFull reads exactly len(b) bytes, reporting io.ErrUnexpectedEOF for a truncated frame.
+func (d *DecryptReader) readFull(b []byte) error {
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return nil
+}
+
+// aead returns the cached AEAD of keyID, fetching the key through lookup the first time.
+func (d *DecryptReader) aead(keyID string) (cipher.AEAD, error) {
+	if aead, ok := d.aeads[keyID]; ok {
+		return aead, nil
+	}
+	key, err := d.lookup(keyID)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	d.aeads[keyID] = aead
+	return aead, nil
+}
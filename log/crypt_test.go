@@ -0,0 +1,72 @@
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecryptReader(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	keys := func() (string, []byte, error) { return "k1", key, nil }
+	lookup := func(keyID string) ([]byte, error) {
+		if keyID != "k1" {
+			return nil, errors.New("unknown key")
+		}
+		return key, nil
+	}
+
+	dir := t.TempDir()
+	rl, err := NewRoteteLog(filepath.Join(dir, "error_%d_%d_%d.log"), WithEncryption(keys))
+	if err != nil {
+		t.Fatal(err)
+	}
+	large := strings.Repeat("x", maxFramePlaintext+100) + "\n"
+	want := "line 1\n" + large + "line 2\n"
+	for _, line := range []string{"line 1\n", large, "line 2\n"} {
+		if _, err := rl.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rl.Close()
+
+	segment, err := os.ReadFile(rl.getNewPath(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(segment, []byte("line 1")) {
+		t.Fatal("segment is not encrypted")
+	}
+
+	d := NewDecryptReader(bytes.NewReader(segment), lookup)
+	got, err := io.ReadAll(d)
+	if err != nil || string(got) != want {
+		t.Fatalf("ReadAll() = %d bytes, %v, want %d bytes", len(got), err, len(want))
+	}
+	if h := d.Headers(); len(h) != 1 || h[0].KeyID != "k1" {
+		t.Errorf("Headers() = %+v, want one header of k1", h)
+	}
+
+	// 崩溃导致最后一帧不完整
+	got, err = io.ReadAll(NewDecryptReader(bytes.NewReader(segment[:len(segment)-5]), lookup))
+	if !errors.Is(err, io.ErrUnexpectedEOF) || string(got) != "line 1\n"+large {
+		t.Errorf("ReadAll() of a truncated segment = %d bytes, %v, want %v after the complete frames", len(got), err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestDecryptReader_FrameTooLarge(t *testing.T) {
+	frame := []byte{frameData, 2, 'k', '1'}
+	frame = append(frame, make([]byte, 12)...)
+	frame = binary.BigEndian.AppendUint32(frame, 0xFFFFFFFF)
+
+	lookup := func(string) ([]byte, error) { return make([]byte, 32), nil }
+	if _, err := io.ReadAll(NewDecryptReader(bytes.NewReader(frame), lookup)); err == nil || errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ReadAll() error = %v, want the frame rejected before reading it", err)
+	}
+}
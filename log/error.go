@@ -0,0 +1,74 @@
+package log
+
+import (
+	awesomeerrors "awesome-pkg/errors"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Err returns a field logging err under the key "error": code, reason, the public message,
+// and the internal message and fileLine if any, for an ErrorIface; the text of err otherwise.
+// Unlike the HTTP renderers of package errors, it records the internal message.
+func Err(err error) zap.Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	return zap.Object("error", errorMarshaler{err})
+}
+
+// errorMarshaler marshals an error as an object.
+type errorMarshaler struct {
+	err error
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (m errorMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	e, ok := m.err.(awesomeerrors.ErrorIface)
+	if !ok {
+		enc.AddString("message", m.err.Error())
+		return nil
+	}
+
+	enc.AddInt("code", e.GetCode())
+	enc.AddString("reason", e.GetReason())
+	enc.AddString("message", e.GetMessage())
+	if internal := awesomeerrors.InternalMessage(e); internal != "" {
+		enc.AddString("internal", internal)
+	}
+	if fileLine := e.GetFileLine(); fileLine != "" {
+		enc.AddString("file", fileLine)
+	}
+	if cause := awesomeerrors.Unwrap(e); cause != nil {
+		enc.AddString("cause", cause.Error())
+	}
+	return nil
+}
+
+// LevelOf returns the level to log err at, derived from its severity, see errors.SeverityOf:
+// info, warn, and error for both errors and critical ones, told apart by the severity field written by LogError.
+func LevelOf(err error) zapcore.Level {
+	switch awesomeerrors.SeverityOf(err) {
+	case awesomeerrors.SeverityInfo:
+		return zapcore.InfoLevel
+	case awesomeerrors.SeverityWarning:
+		return zapcore.WarnLevel
+	}
+	return zapcore.ErrorLevel
+}
+
+// LogError logs err with msg and fields to l at LevelOf(err), along with its severity, so that routing follows the category of err.
+// Critical errors carry NoCoalesce, so that each of them is written even through a coalescing core. Nothing is logged if err is nil.
+func LogError(l *zap.Logger, msg string, err error, fields ...zap.Field) {
+	if err == nil {
+		return
+	}
+	if ce := l.Check(LevelOf(err), msg); ce != nil {
+		severity := awesomeerrors.SeverityOf(err)
+		fields = append(fields, Err(err), zap.String("severity", severity.String()))
+		if severity == awesomeerrors.SeverityCritical {
+			fields = append(fields, NoCoalesce())
+		}
+		ce.Write(fields...)
+	}
+}
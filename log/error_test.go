@@ -0,0 +1,38 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	awesomeerrors "awesome-pkg/errors"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogError_Critical(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	l := zap.New(NewCoalescingCore(obs, time.Hour), zap.Development())
+
+	err := awesomeerrors.New(503, "Unavailable", "", nil)
+	for i := 0; i < 2; i++ {
+		LogError(l, "db down", err)
+	}
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("%d entries, want 2: critical errors bypass coalescing", len(entries))
+	}
+	for _, e := range entries {
+		if e.Level != zapcore.ErrorLevel {
+			t.Errorf("level = %v, want %v", e.Level, zapcore.ErrorLevel)
+		}
+		if got := e.ContextMap()["severity"]; got != awesomeerrors.SeverityCritical.String() {
+			t.Errorf("severity = %v, want %v", got, awesomeerrors.SeverityCritical)
+		}
+		if _, ok := e.ContextMap()[noCoalesceKey]; ok {
+			t.Errorf("NoCoalesce is encoded: %v", e.ContextMap())
+		}
+	}
+}
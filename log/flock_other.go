@@ -0,0 +1,19 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package log
+
+import (
+	"errors"
+	"os"
+)
+
+// errFlockUnsupported is returned when WithMultiProcess is used on a platform without flock.
+var errFlockUnsupported = errors.New("multi-process RotateLog is not supported on this platform")
+
+func flock(f *os.File) error {
+	return errFlockUnsupported
+}
+
+func funlock(f *os.File) error {
+	return errFlockUnsupported
+}
@@ -0,0 +1,70 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateLog_MultiProcessLink(t *testing.T) {
+	dir := t.TempDir()
+	link := filepath.Join(dir, "error.log")
+	newRotateLog := func() *RotateLog {
+		rl, err := NewRoteteLog(filepath.Join(dir, "error_%d_%d_%d.log"), WithLinkPath(link), WithMultiProcess())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { rl.Close() })
+		return rl
+	}
+	// 两个 RotateLog 模拟两个进程
+	rl1, rl2 := newRotateLog(), newRotateLog()
+
+	sameFile := func(a, b string) bool {
+		fa, errA := os.Stat(a)
+		fb, errB := os.Stat(b)
+		return errA == nil && errB == nil && os.SameFile(fa, fb)
+	}
+	segment := rl1.getNewPath(time.Now())
+	if !sameFile(link, segment) {
+		t.Fatalf("link does not point to %s", segment)
+	}
+
+	// 链接指向了旧文件，第一个切割的进程负责切换，第二个发现已切换后保持不变
+	os.Remove(link)
+	if err := os.WriteFile(link, []byte("stale\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl2.rotateFile(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if !sameFile(link, segment) {
+		t.Fatalf("link not switched to %s", segment)
+	}
+	before, _ := os.Stat(link)
+	if err := rl1.rotateFile(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := os.Stat(link); !os.SameFile(before, after) {
+		t.Errorf("link switched twice")
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(tmps) != 0 {
+		t.Errorf("temporary links left: %v", tmps)
+	}
+
+	for i := 0; i < 100; i++ {
+		rl1.Write([]byte("from rl1\n"))
+		rl2.Write([]byte("from rl2\n"))
+	}
+	b, err := os.ReadFile(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n"); len(lines) != 200 {
+		t.Errorf("link has %d lines, want 200", len(lines))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"io"
 	"reflect"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -11,7 +12,8 @@ import (
 )
 
 // newLogger creates and returns a pointer to a new zap logger ^ ^
-func newLogger(scenario, logminlevel string, outstream io.Writer) (zaplogger *zap.Logger, sync func() error, err error) {
+// wrappers are applied to the core in order, e.g. NewCoalescingCore.
+func newLogger(scenario, logminlevel string, outstream io.Writer, wrappers ...func(zapcore.Core) zapcore.Core) (zaplogger *zap.Logger, sync func() error, err error) {
 	// if any of the following steps panics in an unforeseen way, deferred recovery will catch it
 	defer func() {
 		if err := recover(); err != nil {
@@ -31,39 +33,57 @@ func newLogger(scenario, logminlevel string, outstream io.Writer) (zaplogger *za
 	atomicLevel := zap.NewAtomicLevel()
 	atomicLevel.SetLevel(zapLevel)
 	core := zapcore.NewCore(
-		// 配置 时间，等级，caller，stacktrace 键值
-		zapcore.NewJSONEncoder(zapcore.EncoderConfig{
-			TimeKey:        "ts",
-			LevelKey:       "level",
-			CallerKey:      "caller",
-			StacktraceKey:  "stacktrace",
-			EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
-			EncodeDuration: zapcore.MillisDurationEncoder,
-			EncodeLevel:    zapcore.CapitalLevelEncoder,
-			EncodeCaller:   zapcore.ShortCallerEncoder,
-		}),
+		newEncoder(),
 
 		// 多写，文件和 stdout
 		zapcore.NewMultiWriteSyncer(syncers...),
 		atomicLevel,
 	)
+	for _, wrap := range wrappers {
+		core = wrap(core)
+	}
+
+	// create a new zap logger; the AtomicLevel is kept next to the core for per-request escalation
+	zaplogger = zap.New(&leveledCore{Core: core, level: atomicLevel}, loggerOptions()...)
+	sync = zaplogger.Sync
+
+	// RET
+	return
+}
 
-	// create a new zap logger
-	zaplogger = zap.New(core,
+// newEncoder returns the encoder shared by all loggers
+func newEncoder() zapcore.Encoder {
+	// 配置 时间，等级，caller，stacktrace 键值
+	return zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		TimeKey:        "ts",
+		LevelKey:       "level",
+		CallerKey:      "caller",
+		StacktraceKey:  "stacktrace",
+		EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
+		EncodeDuration: zapcore.MillisDurationEncoder,
+		EncodeLevel:    zapcore.CapitalLevelEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	})
+}
+
+// loggerOptions returns the zap options shared by all loggers
+func loggerOptions() []zap.Option {
+	return []zap.Option{
 		zap.AddCaller(),
 		// 0 是 Helper 能获取正确的 caller 的值
 		zap.AddCallerSkip(0),
 		// 自动加上 stacktrace 最小等级
 		zap.AddStacktrace(zapcore.FatalLevel),
-	)
-	sync = zaplogger.Sync
-
-	// RET
-	return
+	}
 }
 
 // Register creates new zap loggers and regists them to global var, then returns zap.Sync() for graceful shutdown
-func Register(logpath, logminlevel string) (syncers []func() error, err error) {
+func Register(logpath, logminlevel string, opts ...RegisterOption) (syncers []func() error, err error) {
+	cfg := &registerConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	// graceful shutdown
 	errFlag := false
 	defer func() {
@@ -80,7 +100,8 @@ func Register(logpath, logminlevel string) (syncers []func() error, err error) {
 		errFlag = true
 		return
 	}
-	el, es, err := newLogger("Error", logminlevel, erl)
+	setRotateLog("Error", erl)
+	el, es, err := newLogger("Error", logminlevel, erl, cfg.wrappers("Error")...)
 	if err != nil {
 		errFlag = true
 		return
@@ -93,7 +114,8 @@ func Register(logpath, logminlevel string) (syncers []func() error, err error) {
 		errFlag = true
 		return
 	}
-	rl, rs, err := newLogger("Request", logminlevel, rrl)
+	setRotateLog("Request", rrl)
+	rl, rs, err := newLogger("Request", logminlevel, rrl, cfg.wrappers("Request")...)
 	if err != nil {
 		errFlag = true
 		return
@@ -106,7 +128,8 @@ func Register(logpath, logminlevel string) (syncers []func() error, err error) {
 		errFlag = true
 		return
 	}
-	cl, cs, err := newLogger("Call", logminlevel, crl)
+	setRotateLog("Call", crl)
+	cl, cs, err := newLogger("Call", logminlevel, crl, cfg.wrappers("Call")...)
 	if err != nil {
 		errFlag = true
 		return
@@ -119,27 +142,140 @@ func Register(logpath, logminlevel string) (syncers []func() error, err error) {
 		errFlag = true
 		return
 	}
-	dl, ds, err := newLogger("Error", "debug", drl)
+	setRotateLog("Debug", drl)
+	dl, ds, err := newLogger("Error", "debug", drl, cfg.wrappers("Debug")...)
 	if err != nil {
 		errFlag = true
 		return
 	}
 	syncers = append(syncers, ds)
 
+	// 注册 Audit Logger，从最新的非空分段的最后一条记录继续哈希链
+	// 重启后链接文件可能已经指向当天新建的空分段，因此不能只读链接文件
+	var al *Audit
+	if AuditLogFile != "" {
+		if rotateLogEncrypted() {
+			errFlag = true
+			return syncers, ErrAuditEncrypted
+		}
+		// 审计日志写入失败必须返回给 Audit.Log，否则哈希链会跳过未落盘的记录
+		arl, err := getRotateLog(logpath+AuditLogFile, withoutFallback())
+		if err != nil {
+			errFlag = true
+			return syncers, err
+		}
+		setRotateLog("Audit", arl)
+		if al, err = ResumeAuditGlob(arl, logpath+AuditLogFile+"_*.log"); err != nil {
+			errFlag = true
+			return syncers, err
+		}
+		syncers = append(syncers, al.Sync)
+	}
+
+	// 注册按等级分流的 Logger，仅当 MainLogFile 非空时注册
+	var ml *zap.Logger
+	if MainLogFile != "" {
+		mrl, err := getRotateLog(logpath + MainLogFile)
+		if err != nil {
+			errFlag = true
+			return syncers, err
+		}
+		setRotateLog("Main", mrl)
+		var ms func() error
+		if ml, ms, err = newRoutedLogger(logminlevel, mrl, erl, drl, cfg.wrappers("Main")...); err != nil {
+			errFlag = true
+			return syncers, err
+		}
+		syncers = append(syncers, ms)
+	}
+
 	// 注册 Logger 们到全局变量中
 	ErrorLogger = el
 	RequestLogger = rl
 	CallLogger = cl
 	DebugLogger = dl
+	AuditLogger = al
+	Logger = ml
 
 	return
 }
 
-// getRotateLog returns a hook created by NewRotateLog
-func getRotateLog(filename string) (*RotateLog, error) {
+// RegisterOption configures the loggers created by Register.
+type RegisterOption func(*registerConfig)
+
+type registerConfig struct {
+	coalesce map[string]time.Duration // coalescing window keyed by scenario
+}
+
+// WithCoalesce summarizes the repeats of identical entries written within window for the logger of scenario,
+// i.e. one of Error, Request, Call, Debug and Main; see NewCoalescingCore.
+func WithCoalesce(scenario string, window time.Duration) RegisterOption {
+	return func(cfg *registerConfig) {
+		if cfg.coalesce == nil {
+			cfg.coalesce = map[string]time.Duration{}
+		}
+		cfg.coalesce[scenario] = window
+	}
+}
+
+// wrappers returns the core wrappers to apply to the logger of scenario.
+func (cfg *registerConfig) wrappers(scenario string) (ret []func(zapcore.Core) zapcore.Core) {
+	if window, ok := cfg.coalesce[scenario]; ok && window > 0 {
+		ret = append(ret, func(c zapcore.Core) zapcore.Core { return NewCoalescingCore(c, window) })
+	}
+	return ret
+}
+
+var (
+	rotateLogsMutex sync.RWMutex
+	rotateLogs      = map[string]*RotateLog{} // RotateLog behind each registered logger, keyed by scenario
+)
+
+// setRotateLog records rl as the RotateLog behind the logger of scenario
+func setRotateLog(scenario string, rl *RotateLog) {
+	rotateLogsMutex.Lock()
+	defer rotateLogsMutex.Unlock()
+	rotateLogs[scenario] = rl
+}
+
+// Health returns the health status of the file behind each registered logger, keyed by scenario
+// (Error, Request, Call, Debug, and Audit and Main when AuditLogFile and MainLogFile are set).
+// A service can use it to report degraded logging. It is safe to call concurrently with Register.
+func Health() map[string]HealthStatus {
+	rotateLogsMutex.RLock()
+	defer rotateLogsMutex.RUnlock()
+	ret := make(map[string]HealthStatus, len(rotateLogs))
+	for scenario, rl := range rotateLogs {
+		ret[scenario] = rl.Health()
+	}
+	return ret
+}
+
+// rotateLogEncrypted reports whether RotateLogOptions enable encryption, see WithEncryption
+func rotateLogEncrypted() bool {
+	probe := &RotateLog{}
+	for _, opt := range RotateLogOptions {
+		opt(probe)
+	}
+	return probe.keyFunc != nil
+}
+
+// getRotateLog returns a hook created by NewRotateLog; extra options are applied after RotateLogOptions
+func getRotateLog(filename string, extra ...Option) (*RotateLog, error) {
+	opts := []Option{
+		WithLinkPath(filename + ".log"),
+		WithRotateTime(time.Minute),
+	}
+	opts = append(opts, RotateLogOptions...)
 	return NewRoteteLog(
 		filename+"_%d_%d_%d.log",
-		WithLinkPath(filename+".log"),
-		WithRotateTime(time.Minute),
+		append(opts, extra...)...,
 	)
 }
+
+// withoutFallback drops FailFallbackStderr from the failure policy, so that failed writes are reported to the writer
+func withoutFallback() Option {
+	return func(r *RotateLog) {
+		r.policy &^= FailFallbackStderr
+	}
+}
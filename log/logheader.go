@@ -11,9 +11,22 @@ var (
 	CallLogger    *zap.Logger
 	DebugLogger   *zap.Logger
 
+	// 按等级分流的 Logger：warn 及以上同时写入错误文件，全部写入主文件，debug 同时写入 debug 文件
+	// 仅当 MainLogFile 非空时注册，可以代替手动选择 ErrorLogger 和 DebugLogger
+	Logger *zap.Logger
+
+	// 审计 Logger，仅当 AuditLogFile 非空时注册
+	AuditLogger *Audit
+
 	// 文件名
 	ErrorLogFile   string
 	RequestLogFile string
 	CallLogFile    string
 	DebugLogFile   string
+	AuditLogFile   string
+	MainLogFile    string
+
+	// 注册 Logger 时额外应用到每个 RotateLog 的选项，例如 WithFailurePolicy
+	// 审计日志不使用 FailFallbackStderr，写入失败总是返回给 Audit.Log；也不支持 WithEncryption
+	RotateLogOptions []Option
 )
@@ -1,11 +1,15 @@
 package log
 
 import (
+	"crypto/cipher"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
+
+	"go.uber.org/multierr"
 )
 
 // RotateLog ...
@@ -19,6 +23,39 @@ type RotateLog struct {
 	mutex  *sync.Mutex
 	rotate <-chan time.Time // notify rotate event
 	close  chan struct{}    // close file and write goroutine
+
+	policy  FailurePolicy
+	onError func(error)
+	health  HealthStatus
+
+	multiProcess bool     // coordinate rotation with other processes through flock
+	lockFile     *os.File // lock file shared by all processes writing to the same logPath
+
+	keyFunc KeyFunc     // encrypt segments if not nil
+	aead    cipher.AEAD // cipher of the current segment
+	keyID   string      // key ID of the current segment
+}
+
+// FailurePolicy 决定写入或切割失败时 RotateLog 的行为，可以按位组合
+type FailurePolicy int
+
+const (
+	// FailFallbackStderr 写文件失败时改写到 os.Stderr，并向调用方报告写入成功
+	FailFallbackStderr FailurePolicy = 1 << iota
+	// FailRetryOnRotate 写文件失败时关闭当前文件，直到下一次切割时重新打开；需要配合 WithRotateTime 使用
+	FailRetryOnRotate
+)
+
+// ErrFileUnavailable is returned by Write when the log file has been closed after a failure and not yet reopened.
+var ErrFileUnavailable = errors.New("log file unavailable until next rotation")
+
+// HealthStatus describes whether a RotateLog is currently able to write to its file.
+type HealthStatus struct {
+	Degraded    bool      // the last write or rotation failed and has not recovered yet
+	Fallback    bool      // the last write went to os.Stderr instead of the file
+	Failures    uint64    // total number of failed writes and rotations
+	LastError   error     // the most recent failure
+	LastErrorAt time.Time // when the most recent failure happened
 }
 
 // 返回 RotateLog 实例
@@ -36,6 +73,20 @@ func NewRoteteLog(logPath string, opts ...Option) (*RotateLog, error) {
 		return nil, err
 	}
 
+	if rl.multiProcess {
+		lf, err := os.OpenFile(rl.lockPath(), os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			return nil, err
+		}
+		// 顺便确认当前平台支持 flock
+		if err := flock(lf); err != nil {
+			lf.Close()
+			return nil, err
+		}
+		funlock(lf)
+		rl.lockFile = lf
+	}
+
 	if err := rl.rotateFile(time.Now()); err != nil {
 		return nil, err
 	}
@@ -50,15 +101,124 @@ func NewRoteteLog(logPath string, opts ...Option) (*RotateLog, error) {
 // 写入日志文件
 func (r *RotateLog) Write(b []byte) (int, error) {
 	r.mutex.Lock()
-	defer r.mutex.Unlock()
-	n, err := r.file.Write(b)
+	n, err := r.write(b)
+	r.mutex.Unlock()
+
+	// 回调放在锁外执行，回调里再写日志也不会死锁
+	if err != nil && r.onError != nil {
+		r.onError(err)
+	}
+	if err != nil && r.policy&FailFallbackStderr != 0 && r.keyFunc == nil {
+		os.Stderr.Write(b)
+		return len(b), nil
+	}
 	return n, err
 }
 
+// write 需要在持有 mutex 的情况下调用
+func (r *RotateLog) write(b []byte) (int, error) {
+	if r.file == nil {
+		r.fail(ErrFileUnavailable)
+		return 0, ErrFileUnavailable
+	}
+
+	// 多进程模式下整行写入期间持有文件锁，保证行与行之间不会交错
+	if r.multiProcess {
+		if err := flock(r.file); err != nil {
+			r.fail(err)
+			return 0, err
+		}
+		defer funlock(r.file)
+	}
+
+	n, err := r.writeFile(b)
+	if err != nil {
+		r.fail(err)
+		if r.policy&FailRetryOnRotate != 0 {
+			r.file.Close()
+			r.file = nil
+		}
+		return n, err
+	}
+
+	r.health.Degraded = false
+	r.health.Fallback = false
+	return n, nil
+}
+
+// writeFile writes b to the current file, sealing it into data frames if encryption is enabled
+func (r *RotateLog) writeFile(b []byte) (int, error) {
+	if r.aead == nil {
+		return r.file.Write(b)
+	}
+	frames, err := sealFrames(r.aead, r.keyID, b)
+	if err != nil {
+		return 0, err
+	}
+	if err := appendFrames(r.file, frames); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// appendFrames appends encrypted frames to file, truncating file back to its previous size if the write fails,
+// so that a partial frame never precedes the next one. In multi-process mode, the lock on file must be held.
+func appendFrames(file *os.File, frames []byte) error {
+	fi, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(frames); err != nil {
+		// 写入了部分帧时截断，否则之后的帧都无法读取
+		return multierr.Append(err, file.Truncate(fi.Size()))
+	}
+	return nil
+}
+
+// fail 记录一次失败，需要在持有 mutex 的情况下调用
+func (r *RotateLog) fail(err error) {
+	r.health.Degraded = true
+	r.health.Fallback = r.policy&FailFallbackStderr != 0 && r.keyFunc == nil
+	r.health.Failures++
+	r.health.LastError = err
+	r.health.LastErrorAt = time.Now()
+}
+
+// Sync commits the current log file to stable storage.
+func (r *RotateLog) Sync() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Sync()
+}
+
+// Health returns a snapshot of the current health status.
+func (r *RotateLog) Health() HealthStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.health
+}
+
 // 关闭日志文件
 func (r *RotateLog) Close() error {
-	r.close <- struct{}{}
-	return r.file.Close()
+	if r.rotateTime != 0 {
+		r.close <- struct{}{}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.lockFile != nil {
+		r.lockFile.Close()
+		r.lockFile = nil
+	}
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
 }
 
 // 优雅处理
@@ -68,7 +228,9 @@ func (r *RotateLog) handleEvent() {
 		case <-r.close:
 			return
 		case now := <-r.rotate:
-			r.rotateFile(now)
+			if err := r.rotateFile(now); err != nil && r.onError != nil {
+				r.onError(err)
+			}
 		}
 	}
 }
@@ -87,15 +249,37 @@ func (r *RotateLog) rotateFile(now time.Time) error {
 
 	file, err := os.OpenFile(newPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0755)
 	if err != nil {
+		// 保留旧文件继续写，下一次切割时再尝试
+		r.fail(err)
 		return err
 	}
+
+	// 加密模式下每次打开文件都写入一个记录当前密钥的头部帧
+	if r.keyFunc != nil {
+		aead, keyID, header, err := newSegmentCipher(r.keyFunc, now)
+		if err == nil {
+			err = r.appendHeader(file, header)
+		}
+		if err != nil {
+			file.Close()
+			r.fail(err)
+			return err
+		}
+		r.aead, r.keyID = aead, keyID
+	}
+
 	if r.file != nil {
 		r.file.Close()
 	}
 
 	r.file = file
+	r.health.Degraded = false
+	r.health.Fallback = false
 
 	if len(r.curLink) > 0 {
+		if r.multiProcess {
+			return r.switchLinkShared(newPath)
+		}
 		os.Remove(r.curLink)
 		os.Link(newPath, r.curLink)
 	}
@@ -103,6 +287,53 @@ func (r *RotateLog) rotateFile(now time.Time) error {
 	return nil
 }
 
+// appendHeader appends the header frame to the newly opened file, holding its lock in multi-process mode.
+func (r *RotateLog) appendHeader(file *os.File, header []byte) error {
+	if r.multiProcess {
+		if err := flock(file); err != nil {
+			return err
+		}
+		defer funlock(file)
+	}
+	return appendFrames(file, header)
+}
+
+// switchLinkShared points curLink to newPath while holding the shared lock file,
+// so that only the first process reaching a rotation actually switches the link.
+// The link is replaced through rename, hence never missing for readers.
+func (r *RotateLog) switchLinkShared(newPath string) error {
+	if err := flock(r.lockFile); err != nil {
+		return err
+	}
+	defer funlock(r.lockFile)
+
+	// 其他进程已经完成了切换
+	if linkFi, err := os.Stat(r.curLink); err == nil {
+		if newFi, err := os.Stat(newPath); err == nil && os.SameFile(linkFi, newFi) {
+			return nil
+		}
+	}
+
+	tmp := fmt.Sprintf("%s.%d.tmp", r.curLink, os.Getpid())
+	os.Remove(tmp)
+	if err := os.Link(newPath, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, r.curLink); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// lockPath returns the path of the lock file shared by all processes writing to the same log.
+func (r *RotateLog) lockPath() string {
+	if len(r.curLink) > 0 {
+		return r.curLink + ".lock"
+	}
+	return r.logPath + ".lock"
+}
+
 // 根据时间，生成最新的日志文件名
 func (r *RotateLog) getNewPath(t time.Time) string {
 	return fmt.Sprintf(r.logPath, time.Now().Year(), int(time.Now().Month()), time.Now().Day())
@@ -123,6 +354,31 @@ func WithLinkPath(lp string) Option {
 	}
 }
 
+// WithMultiProcess makes several processes logging to the same path safe to use together:
+// rotation and link switching are serialized through an advisory lock file (flock),
+// and each Write holds a lock on the segment so that lines never interleave.
+// Every process opens the same time-based segment on rotation, hence they all converge on the new file.
+func WithMultiProcess() Option {
+	return func(r *RotateLog) {
+		r.multiProcess = true
+	}
+}
+
+// WithFailurePolicy sets how write and rotation failures are handled; see FailurePolicy.
+func WithFailurePolicy(policy FailurePolicy) Option {
+	return func(r *RotateLog) {
+		r.policy = policy
+	}
+}
+
+// WithErrorHandler registers a callback invoked on every failed write or rotation.
+// The callback is called without holding the internal lock.
+func WithErrorHandler(f func(error)) Option {
+	return func(r *RotateLog) {
+		r.onError = f
+	}
+}
+
 // Helper ...
 // CalcNextRotate returns the count down til the next rotation
 func CalcNextRotate(now time.Time, next time.Duration) time.Duration {
@@ -0,0 +1,73 @@
+package log
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotateLog_FailurePolicy(t *testing.T) {
+	stderr := os.Stderr
+	defer func() { os.Stderr = stderr }()
+	fallback, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fallback.Close()
+	os.Stderr = fallback
+
+	rl, err := NewRoteteLog(filepath.Join(t.TempDir(), "error_%d_%d_%d.log"), WithFailurePolicy(FailFallbackStderr|FailRetryOnRotate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+
+	// 模拟磁盘故障：底层文件被意外关闭
+	rl.file.Close()
+	if n, err := rl.Write([]byte("lost\n")); err != nil || n != 5 {
+		t.Fatalf("Write() = %d, %v, want the fallback to report success", n, err)
+	}
+	if h := rl.Health(); !h.Degraded || !h.Fallback || h.Failures != 1 || h.LastError == nil {
+		t.Errorf("Health() = %+v, want degraded with fallback", h)
+	}
+	if b, _ := os.ReadFile(fallback.Name()); string(b) != "lost\n" {
+		t.Errorf("stderr = %q, want the failed line", b)
+	}
+
+	// FailRetryOnRotate 关闭了文件，下一次写入直接失败
+	rl.Write([]byte("lost again\n"))
+	if h := rl.Health(); !errors.Is(h.LastError, ErrFileUnavailable) || h.Failures != 2 {
+		t.Errorf("Health() = %+v, want %v", h, ErrFileUnavailable)
+	}
+
+	// 切割时重新打开文件后恢复
+	if err := rl.rotateFile(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rl.Write([]byte("ok\n")); err != nil {
+		t.Fatal(err)
+	}
+	if h := rl.Health(); h.Degraded || h.Fallback || h.Failures != 2 {
+		t.Errorf("Health() = %+v, want recovered", h)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	defer func(saved map[string]*RotateLog) { rotateLogs = saved }(rotateLogs)
+	rotateLogs = map[string]*RotateLog{}
+
+	rl, err := NewRoteteLog(filepath.Join(t.TempDir(), "error_%d_%d_%d.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rl.Close()
+	setRotateLog("Error", rl)
+
+	rl.file.Close()
+	rl.Write([]byte("lost\n"))
+	if h, ok := Health()["Error"]; !ok || !h.Degraded || h.Fallback {
+		t.Errorf("Health()[Error] = %+v, %v, want degraded without fallback", h, ok)
+	}
+}
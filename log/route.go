@@ -0,0 +1,112 @@
+package log
+
+import (
+	"io"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newRoutedLogger creates a zap logger routing each entry by its level within a single logger:
+// warn and above to errstream, everything to mainstream, and debug to debugstream.
+func newRoutedLogger(logminlevel string, mainstream, errstream, debugstream io.Writer, wrappers ...func(zapcore.Core) zapcore.Core) (zaplogger *zap.Logger, sync func() error, err error) {
+	var zapLevel zapcore.Level
+	if err := zapLevel.Set(logminlevel); err != nil {
+		return nil, nil, err
+	}
+	atomicLevel := zap.NewAtomicLevel()
+	atomicLevel.SetLevel(zapLevel)
+
+	var core zapcore.Core = newRouteCore(atomicLevel,
+		Route(zapcore.DebugLevel, mainstream),
+		Route(zap.LevelEnablerFunc(func(l zapcore.Level) bool { return l >= zapcore.WarnLevel }), errstream),
+		Route(zap.LevelEnablerFunc(func(l zapcore.Level) bool { return l == zapcore.DebugLevel }), debugstream),
+	)
+	for _, wrap := range wrappers {
+		core = wrap(core)
+	}
+
+	zaplogger = zap.New(&leveledCore{Core: core, level: atomicLevel}, loggerOptions()...)
+	sync = zaplogger.Sync
+	return
+}
+
+// RouteSpec sends the entries accepted by its enabler to one destination.
+type RouteSpec struct {
+	enab zapcore.LevelEnabler
+	core zapcore.Core
+}
+
+// Route returns a RouteSpec writing the entries whose level is enabled by enab to outstream, with the shared encoder.
+func Route(enab zapcore.LevelEnabler, outstream io.Writer) RouteSpec {
+	// 级别由 RouteSpec 自己判断，底层 core 接收所有级别
+	return RouteSpec{enab: enab, core: zapcore.NewCore(newEncoder(), zapcore.AddSync(outstream), zapcore.DebugLevel)}
+}
+
+// NewRouted returns a logger routing entries by level to the given routes, gated by level as a whole.
+// Each entry is written once to every route that accepts its level.
+func NewRouted(level zap.AtomicLevel, routes ...RouteSpec) *zap.Logger {
+	return zap.New(&leveledCore{Core: newRouteCore(level, routes...), level: level}, loggerOptions()...)
+}
+
+// routeCore is a zapcore.Core dispatching each entry to the routes accepting its level.
+// Unlike zapcore.NewTee, Write also routes by level, so that escalated entries written directly still go to the right files.
+type routeCore struct {
+	level  zapcore.LevelEnabler
+	routes []RouteSpec
+}
+
+func newRouteCore(level zapcore.LevelEnabler, routes ...RouteSpec) *routeCore {
+	return &routeCore{level: level, routes: routes}
+}
+
+// Enabled ...
+func (c *routeCore) Enabled(l zapcore.Level) bool {
+	return c.level.Enabled(l) && c.routed(l)
+}
+
+// routed reports whether any route accepts l.
+func (c *routeCore) routed(l zapcore.Level) bool {
+	for _, r := range c.routes {
+		if r.enab.Enabled(l) {
+			return true
+		}
+	}
+	return false
+}
+
+// With ...
+func (c *routeCore) With(fields []zapcore.Field) zapcore.Core {
+	routes := make([]RouteSpec, len(c.routes))
+	for i, r := range c.routes {
+		routes[i] = RouteSpec{enab: r.enab, core: r.core.With(fields)}
+	}
+	return &routeCore{level: c.level, routes: routes}
+}
+
+// Check ...
+func (c *routeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write ...
+func (c *routeCore) Write(ent zapcore.Entry, fields []zapcore.Field) (err error) {
+	for _, r := range c.routes {
+		if r.enab.Enabled(ent.Level) {
+			err = multierr.Append(err, r.core.Write(ent, fields))
+		}
+	}
+	return err
+}
+
+// Sync ...
+func (c *routeCore) Sync() (err error) {
+	for _, r := range c.routes {
+		err = multierr.Append(err, r.core.Sync())
+	}
+	return err
+}
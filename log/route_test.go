@@ -0,0 +1,51 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestRoutedLogger(t *testing.T) {
+	var main, errs, debug bytes.Buffer
+	l, _, err := newRoutedLogger("info", &main, &errs, &debug)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Debug("d", zap.String("id", "debug"))
+	l.Info("i", zap.String("id", "info"))
+	l.Warn("w", zap.String("id", "warn"))
+	l.Error("e", zap.String("id", "error"))
+	// 按请求提升的 Debug 条目也要按级别分流
+	Ctx(WithDebug(context.Background()), l).Debug("d", zap.String("id", "escalated"))
+
+	tests := []struct {
+		name string
+		out  *bytes.Buffer
+		want []string
+		not  []string
+	}{
+		{name: "main", out: &main, want: []string{"info", "warn", "error", "escalated"}, not: []string{"debug"}},
+		{name: "error", out: &errs, want: []string{"warn", "error"}, not: []string{"debug", "info", "escalated"}},
+		{name: "debug", out: &debug, want: []string{"escalated"}, not: []string{"debug", "info", "warn", "error"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := tt.out.String()
+			for _, id := range tt.want {
+				if !strings.Contains(out, `"id":"`+id+`"`) {
+					t.Errorf("%s output = %s, want %s", tt.name, out, id)
+				}
+			}
+			for _, id := range tt.not {
+				if strings.Contains(out, `"id":"`+id+`"`) {
+					t.Errorf("%s output = %s, want no %s", tt.name, out, id)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package log
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// W3C trace context headers, see https://www.w3.org/TR/trace-context/
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+// TraceContext is the W3C trace context of a request as seen by this service.
+type TraceContext struct {
+	TraceID  string // 32 lowercase hex digits
+	SpanID   string // 16 lowercase hex digits, the span of this service
+	ParentID string // span ID of the caller, empty if the trace starts here
+	Flags    byte   // trace flags, e.g. 0x01 for sampled
+	State    string // tracestate, propagated as is
+}
+
+// NewTrace returns a TraceContext starting a new trace.
+func NewTrace() TraceContext {
+	return TraceContext{TraceID: randomHex(16), SpanID: randomHex(8)}
+}
+
+// ParseTraceparent parses a traceparent header value; the returned TraceContext has the parent's span as SpanID.
+func ParseTraceparent(s string) (tc TraceContext, ok bool) {
+	s = strings.TrimSpace(s)
+	// version-traceid-parentid-flags，更高版本可能在后面追加字段
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return tc, false
+	}
+	version, traceID, spanID, flags := s[:2], s[3:35], s[36:52], s[53:55]
+	switch {
+	case !isHex(version) || version == "ff",
+		version == "00" && len(s) != 55,
+		len(s) > 55 && s[55] != '-',
+		!isHex(traceID) || traceID == strings.Repeat("0", 32),
+		!isHex(spanID) || spanID == strings.Repeat("0", 16),
+		!isHex(flags):
+		return tc, false
+	}
+	b, _ := hex.DecodeString(flags)
+	return TraceContext{TraceID: traceID, SpanID: spanID, Flags: b[0]}, true
+}
+
+// Traceparent returns the traceparent header value identifying tc's span.
+func (tc TraceContext) Traceparent() string {
+	return "00-" + tc.TraceID + "-" + tc.SpanID + "-" + hex.EncodeToString([]byte{tc.Flags})
+}
+
+// Child returns a new span of the same trace, child of tc's span.
+func (tc TraceContext) Child() TraceContext {
+	return TraceContext{TraceID: tc.TraceID, SpanID: randomHex(8), ParentID: tc.SpanID, Flags: tc.Flags, State: tc.State}
+}
+
+// Fields returns the zap fields added to every entry of the request.
+func (tc TraceContext) Fields() []zap.Field {
+	fields := []zap.Field{zap.String("trace_id", tc.TraceID), zap.String("span_id", tc.SpanID)}
+	if tc.ParentID != "" {
+		fields = append(fields, zap.String("parent_span_id", tc.ParentID))
+	}
+	return fields
+}
+
+// Inject sets the traceparent and tracestate headers of h from tc.
+func (tc TraceContext) Inject(h http.Header) {
+	h.Set(TraceparentHeader, tc.Traceparent())
+	if tc.State != "" {
+		h.Set(TracestateHeader, tc.State)
+	} else {
+		h.Del(TracestateHeader)
+	}
+}
+
+type traceCtxKey struct{}
+
+// WithTrace returns a copy of ctx carrying tc; log calls made through Ctx then carry trace_id and span_id.
+func WithTrace(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceCtxKey{}, tc)
+}
+
+// TraceFrom returns the TraceContext carried by ctx.
+func TraceFrom(ctx context.Context) (tc TraceContext, ok bool) {
+	tc, ok = ctx.Value(traceCtxKey{}).(TraceContext)
+	return
+}
+
+// TraceMiddleware parses the incoming traceparent and tracestate headers and starts this service's span as a child,
+// or starts a new trace if they are missing or invalid. The TraceContext is carried by the request context.
+func TraceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc := NewTrace()
+		if parent, ok := ParseTraceparent(r.Header.Get(TraceparentHeader)); ok {
+			parent.State = strings.Join(r.Header.Values(TracestateHeader), ",")
+			tc = parent.Child()
+		}
+		next.ServeHTTP(w, r.WithContext(WithTrace(r.Context(), tc)))
+	})
+}
+
+// TraceTransport is an http.RoundTripper propagating the trace context of the request context to outbound requests.
+// Each outbound request carries a new child span ID, and is logged to CallLogger with it if CallLogger is registered.
+type TraceTransport struct {
+	Base http.RoundTripper // http.DefaultTransport if nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *TraceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	parent, ok := TraceFrom(req.Context())
+	if !ok {
+		return base.RoundTrip(req)
+	}
+	span := parent.Child()
+
+	// RoundTripper 不应修改原请求
+	req = req.Clone(req.Context())
+	span.Inject(req.Header)
+
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	if CallLogger != nil {
+		// 记录子 span 的 ID，而不是 Ctx 会添加的当前 span 的 ID
+		l := escalate(req.Context(), CallLogger)
+		fields := append(span.Fields(),
+			zap.String("method", req.Method),
+			zap.String("url", req.URL.String()),
+			zap.Duration("duration", time.Since(start)),
+		)
+		if err != nil {
+			l.Error("outbound request", append(fields, zap.Error(err))...)
+		} else {
+			l.Info("outbound request", append(fields, zap.Int("status", resp.StatusCode))...)
+		}
+	}
+	return resp, err
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	for {
+		rand.Read(b)
+		for _, c := range b {
+			if c != 0 {
+				return hex.EncodeToString(b)
+			}
+		}
+	}
+}
+
+// isHex reports whether s only contains lowercase hex digits.
+func isHex(s string) bool {
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
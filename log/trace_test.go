@@ -0,0 +1,64 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	const valid = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "case1", header: valid, want: true},
+		{name: "case2", header: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", want: true},
+		{name: "case3", header: valid + "-extra", want: false},
+		{name: "case4", header: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", want: false},
+		{name: "case5", header: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", want: false},
+		{name: "case6", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", want: false},
+		{name: "case7", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc, ok := ParseTraceparent(tt.header)
+			if ok != tt.want {
+				t.Fatalf("ParseTraceparent() ok = %v, want %v", ok, tt.want)
+			}
+			if ok && tt.header == valid && tc.Traceparent() != valid {
+				t.Errorf("Traceparent() = %v, want %v", tc.Traceparent(), valid)
+			}
+		})
+	}
+}
+
+func TestTraceContext_Child(t *testing.T) {
+	parent, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	parent.State = "vendor=1"
+	child := parent.Child()
+
+	if child.TraceID != parent.TraceID || child.ParentID != parent.SpanID || child.Flags != parent.Flags || child.State != parent.State {
+		t.Errorf("Child() = %+v, want the same trace under %s", child, parent.SpanID)
+	}
+	if child.SpanID == parent.SpanID || len(child.SpanID) != 16 || !isHex(child.SpanID) {
+		t.Errorf("Child().SpanID = %q, want a new span ID", child.SpanID)
+	}
+	if got, ok := ParseTraceparent(child.Traceparent()); !ok || got.SpanID != child.SpanID {
+		t.Errorf("ParseTraceparent(Child().Traceparent()) = %+v, %v", got, ok)
+	}
+
+	var buf bytes.Buffer
+	l, _, err := newLogger("Request", "info", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Ctx(WithTrace(context.Background(), child), l).Info("request")
+	for _, want := range []string{`"trace_id":"` + child.TraceID, `"span_id":"` + child.SpanID, `"parent_span_id":"` + parent.SpanID} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output = %s, want %s", buf.String(), want)
+		}
+	}
+}